@@ -1,6 +1,8 @@
 package http
 
 const (
+	HeaderAccept                          = "Accept"
+	HeaderAcceptLanguage                  = "Accept-Language"
 	HeaderAcceptEncoding                  = "Accept-Encoding"
 	HeaderXRequestID                      = "X-Request-ID"
 	HeaderETag                            = "ETag"
@@ -19,4 +21,7 @@ const (
 	HeaderContentSecurityPolicyReportOnly = "Content-Security-Policy-Report-Only"
 	HeaderReferrerPolicy                  = "Referrer-Policy"
 	HeaderVary                            = "Vary"
+	HeaderAuthorization                   = "Authorization"
+	HeaderWWWAuthenticate                 = "WWW-Authenticate"
+	HeaderXAPIKey                         = "X-API-Key"
 )
@@ -0,0 +1,344 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+)
+
+// Source is a part of the request that struct fields can be bound from
+type Source uint8
+
+const (
+	SourcePath Source = iota
+	SourceQuery
+	SourceForm
+	SourceHeader
+	SourceCookie
+)
+
+type (
+	BindOptsFn func(*bindConfig)
+	bindConfig struct {
+		// precedence defines order in which sources are looked up when field is tagged for more than one source
+		//
+		// Optional, Default: DefaultPrecedence
+		precedence []Source
+		// collectErrors makes Bind continue after first failed field and return ValidationErrors
+		//
+		// Optional, Default: false
+		collectErrors bool
+		// maxIndex is highest slice index accepted by BindForm
+		//
+		// Optional, Default: 100
+		maxIndex int
+		// errorFunc creates binding errors, like ValueBinder.ErrorFunc
+		//
+		// Optional, Default: NewBindingError
+		errorFunc func(sourceParam string, values []string, message interface{}, internalError error) error
+	}
+	fieldSource struct {
+		source Source
+		key    string
+	}
+)
+
+// ErrUnsupportedType is returned by Bind when struct field has type that can't be bound
+var ErrUnsupportedType = errors.New("binding: Unsupported field type")
+
+// DefaultPrecedence is order in which sources are looked up by Bind when no precedence is configured
+var DefaultPrecedence = []Source{SourcePath, SourceForm, SourceQuery, SourceHeader, SourceCookie}
+
+var (
+	uuidType     = reflect.TypeFor[uuid.UUID]()
+	timeType     = reflect.TypeFor[time.Time]()
+	bindableType = reflect.TypeFor[IBindable]()
+)
+
+var sourceTags = [...]string{
+	SourcePath:   "path",
+	SourceQuery:  "query",
+	SourceForm:   "form",
+	SourceHeader: "header",
+	SourceCookie: "cookie",
+}
+
+// WithPrecedence sets order in which sources are looked up. Sources left out are never used.
+func WithPrecedence(sources ...Source) BindOptsFn {
+	return func(c *bindConfig) {
+		c.precedence = sources
+	}
+}
+
+// WithCollectErrors makes Bind bind all fields and return ValidationErrors listing every failed field
+func WithCollectErrors() BindOptsFn {
+	return func(c *bindConfig) {
+		c.collectErrors = true
+	}
+}
+
+// WithBindErrorFunc sets function creating binding errors of Bind and BindForm, like ValueBinder.ErrorFunc
+func WithBindErrorFunc(fn func(sourceParam string, values []string, message interface{}, internalError error) error) BindOptsFn {
+	return func(c *bindConfig) {
+		c.errorFunc = fn
+	}
+}
+
+func (s Source) String() string {
+	if int(s) < len(sourceTags) {
+		return sourceTags[s]
+	}
+	return ""
+}
+
+// Bind binds request values into struct pointed to by dst using struct tags `path`, `query`, `form`, `header`
+// and `cookie`.
+// Tag value is parameter name, optionally followed by `,required`. Time fields use layout from `layout` tag
+// (default time.RFC3339). Slice fields take all parameter values, comma separated values included unless layout
+// of time slice contains comma.
+// Untagged struct fields are walked recursively.
+//
+// When field is tagged for multiple sources, first source (in precedence order) having non-empty value is used.
+//
+//	type request struct {
+//		ID    uuid.UUID `path:"id,required"`
+//		Name  string    `query:"name" form:"name"`
+//		Token string    `header:"X-Token"`
+//		From  time.Time `query:"from" layout:"2006-01-02"`
+//	}
+func Bind(c *gin.Context, dst any, opts ...BindOptsFn) error {
+	return bind(dst, map[Source]*ValueBinder{
+		SourcePath:   PathParamsBinder(c),
+		SourceQuery:  QueryParamsBinder(c),
+		SourceForm:   FormFieldBinder(c),
+		SourceHeader: HeaderBinder(c),
+		SourceCookie: CookieBinder(c),
+	}, opts)
+}
+
+func bind(dst any, binders map[Source]*ValueBinder, opts []BindOptsFn) error {
+	cfg := &bindConfig{precedence: DefaultPrecedence}
+	for i := range opts {
+		opts[i](cfg)
+	}
+	if cfg.errorFunc != nil {
+		for _, b := range binders {
+			b.ErrorFunc = cfg.errorFunc
+		}
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: dst must be non-nil pointer to struct, got %T", dst)
+	}
+	if !cfg.collectErrors {
+		return bindStruct(v.Elem(), binders, cfg, nil)
+	}
+
+	var errs ValidationErrors
+	if err := bindStruct(v.Elem(), binders, cfg, &errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// bindStruct binds all fields of struct. When errs is not nil binding errors are collected into it
+// and only errors not caused by request values are returned.
+func bindStruct(v reflect.Value, binders map[Source]*ValueBinder, cfg *bindConfig, errs *ValidationErrors) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		// embedded structs of unexported type still promote their exported fields
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		sources, required, skip := parseFieldTags(sf.Tag, cfg.precedence)
+		if skip {
+			continue
+		}
+		fv := v.Field(i)
+		if len(sources) == 0 {
+			if isNestedStruct(fv) {
+				if err := bindStruct(fv, binders, cfg, errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := bindField(fv, sf, sources, required, binders); err != nil {
+			if errs == nil || errors.Is(err, ErrUnsupportedType) {
+				return err
+			}
+			errs.Add(err)
+		}
+	}
+	return nil
+}
+
+func bindField(v reflect.Value, sf reflect.StructField, sources []fieldSource, required bool, binders map[Source]*ValueBinder) error {
+	src := sources[0]
+	found := false
+	for _, s := range sources {
+		if hasValue(binders[s.source], s.key, v.Kind() == reflect.Slice) {
+			src, found = s, true
+			break
+		}
+	}
+	if !found && !required {
+		return nil
+	}
+
+	vb := binders[src.source]
+	if v.Kind() == reflect.Pointer {
+		n := reflect.New(v.Type().Elem())
+		if err := bindValue(vb, src.key, n.Elem(), sf, required); err != nil {
+			return err
+		}
+		if found {
+			v.Set(n)
+		}
+		return nil
+	}
+	return bindValue(vb, src.key, v, sf, required)
+}
+
+func bindValue(vb *ValueBinder, key string, v reflect.Value, sf reflect.StructField, required bool) error {
+	switch dest := v.Addr().Interface().(type) {
+	case IBindable:
+		if required {
+			vb.Custom(key, dest)
+		} else {
+			vb.ShouldCustom(key, dest)
+		}
+	case *uuid.UUID:
+		if required {
+			vb.UUID(key, dest)
+		} else {
+			vb.ShouldUUID(key, dest)
+		}
+	case *time.Time:
+		if required {
+			vb.Time(key, dest, timeLayout(sf))
+		} else {
+			vb.ShouldTime(key, dest, timeLayout(sf))
+		}
+	case *time.Duration:
+		if required {
+			vb.Duration(key, dest)
+		} else {
+			vb.ShouldDuration(key, dest)
+		}
+	case *[]string:
+		if required {
+			vb.Strings(key, dest)
+		} else {
+			vb.ShouldStrings(key, dest)
+		}
+	case *[]int:
+		if required {
+			vb.Ints(key, dest)
+		} else {
+			vb.ShouldInts(key, dest)
+		}
+	case *[]uuid.UUID:
+		if required {
+			vb.UUIDs(key, dest)
+		} else {
+			vb.ShouldUUIDs(key, dest)
+		}
+	case *[]time.Time:
+		if required {
+			vb.Times(key, dest, timeLayout(sf))
+		} else {
+			vb.ShouldTimes(key, dest, timeLayout(sf))
+		}
+	default:
+		switch v.Kind() {
+		case reflect.String:
+			var s string
+			if required {
+				vb.String(key, &s)
+			} else {
+				vb.ShouldString(key, &s)
+			}
+			v.SetString(s)
+		case reflect.Bool:
+			var b bool
+			if required {
+				vb.Bool(key, &b)
+			} else {
+				vb.ShouldBool(key, &b)
+			}
+			v.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var n int64
+			intValue(vb, key, &n, v.Type().Bits(), required)
+			v.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var n uint64
+			uintValue(vb, key, &n, v.Type().Bits(), required)
+			v.SetUint(n)
+		case reflect.Float32, reflect.Float64:
+			var n float64
+			floatValue(vb, key, &n, v.Type().Bits(), required)
+			v.SetFloat(n)
+		default:
+			return fmt.Errorf("%w %s of %s", ErrUnsupportedType, v.Type(), sf.Name)
+		}
+	}
+	return vb.BindError()
+}
+
+// parseFieldTags returns sources field is tagged with ordered by precedence
+func parseFieldTags(tag reflect.StructTag, precedence []Source) (sources []fieldSource, required bool, skip bool) {
+	for _, s := range precedence {
+		value, ok := tag.Lookup(s.String())
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(value, ",")
+		if name == "-" {
+			return nil, false, true
+		}
+		if name == "" {
+			continue
+		}
+		if opts == "required" {
+			required = true
+		}
+		sources = append(sources, fieldSource{source: s, key: name})
+	}
+	return
+}
+
+func hasValue(vb *ValueBinder, key string, multiple bool) bool {
+	if multiple {
+		return len(vb.ValuesFunc(key)) > 0
+	}
+	return vb.ValueFunc(key) != ""
+}
+
+func timeLayout(sf reflect.StructField) string {
+	if layout := sf.Tag.Get("layout"); layout != "" {
+		return layout
+	}
+	return time.RFC3339
+}
+
+func isNestedStruct(v reflect.Value) bool {
+	t := v.Type()
+	if t.Kind() != reflect.Struct || t == uuidType || t == timeType {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(bindableType)
+}
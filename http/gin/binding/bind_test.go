@@ -0,0 +1,159 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type testUpper string
+
+func (u *testUpper) Parse(s string) error {
+	*u = testUpper(strings.ToUpper(s))
+	return nil
+}
+
+func newTestContext(t *testing.T, method, target string, body url.Values) *gin.Context {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if body != nil {
+		c.Request = httptest.NewRequestWithContext(t.Context(), method, target, strings.NewReader(body.Encode()))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		c.Request = httptest.NewRequestWithContext(t.Context(), method, target, nil)
+	}
+	return c
+}
+
+func Test_Bind_Sources(t *testing.T) {
+	t.Parallel()
+	type nested struct {
+		Code testUpper `query:"code"`
+	}
+	type request struct {
+		ID      uuid.UUID  `path:"id,required"`
+		Name    string     `query:"name" form:"name"`
+		Token   string     `header:"X-Token"`
+		Active  bool       `query:"active"`
+		From    time.Time  `query:"from" layout:"2006-01-02"`
+		To      *time.Time `query:"to" layout:"2006-01-02"`
+		Ignored string     `query:"-"`
+		nested
+	}
+
+	id := uuid.Must(uuid.NewV4())
+	c := newTestContext(t, http.MethodPost, "/?name=query&active=true&from=2024-05-01&code=abc&Ignored=x", url.Values{"name": {"form"}})
+	c.Params = gin.Params{{Key: "id", Value: id.String()}}
+	c.Request.Header.Set("X-Token", "secret")
+
+	var dst request
+	require.NoError(t, Bind(c, &dst))
+	require.Equal(t, id, dst.ID)
+	require.Equal(t, "form", dst.Name)
+	require.Equal(t, "secret", dst.Token)
+	require.True(t, dst.Active)
+	require.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), dst.From)
+	require.Nil(t, dst.To)
+	require.Empty(t, dst.Ignored)
+	require.Equal(t, testUpper("ABC"), dst.Code)
+}
+
+func Test_Bind_Numbers(t *testing.T) {
+	t.Parallel()
+	type level uint8
+	type request struct {
+		Page    int           `query:"page"`
+		Level   level         `query:"level"`
+		Price   float32       `query:"price"`
+		Timeout time.Duration `query:"timeout"`
+		IDs     []int         `query:"ids"`
+	}
+
+	c := newTestContext(t, http.MethodGet, "/?page=2&level=3&price=9.5&timeout=5s&ids=1,2&ids=3", nil)
+	var dst request
+	require.NoError(t, Bind(c, &dst))
+	require.Equal(t, request{Page: 2, Level: 3, Price: 9.5, Timeout: 5 * time.Second, IDs: []int{1, 2, 3}}, dst)
+
+	c = newTestContext(t, http.MethodGet, "/?level=256", nil)
+	var be *BindingError
+	require.ErrorAs(t, Bind(c, &dst), &be)
+	require.Equal(t, "level", be.Field)
+}
+
+func Test_Bind_Precedence(t *testing.T) {
+	t.Parallel()
+	type request struct {
+		Name string `query:"name" form:"name"`
+	}
+
+	c := newTestContext(t, http.MethodPost, "/?name=query", url.Values{"name": {"form"}})
+	var dst request
+	require.NoError(t, Bind(c, &dst, WithPrecedence(SourceQuery, SourceForm)))
+	require.Equal(t, "query", dst.Name)
+}
+
+func Test_Bind_Errors(t *testing.T) {
+	t.Parallel()
+	type request struct {
+		ID   uuid.UUID `path:"id,required"`
+		Name string    `query:"name,required"`
+	}
+
+	t.Run("required", func(t *testing.T) {
+		t.Parallel()
+		c := newTestContext(t, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "id", Value: uuid.Must(uuid.NewV4()).String()}}
+
+		var be *BindingError
+		require.ErrorAs(t, Bind(c, new(request)), &be)
+		require.Equal(t, "name", be.Field)
+		require.Equal(t, http.StatusBadRequest, be.Code)
+	})
+	t.Run("custom error", func(t *testing.T) {
+		t.Parallel()
+		c := newTestContext(t, http.MethodGet, "/?name=a", nil)
+		c.Params = gin.Params{{Key: "id", Value: "not-uuid"}}
+
+		var ce *testCustomError
+		require.ErrorAs(t, Bind(c, new(request), WithBindErrorFunc(testCustomErrorFunc)), &ce)
+		require.Equal(t, "id", ce.field)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		c := newTestContext(t, http.MethodGet, "/?name=a", nil)
+		c.Params = gin.Params{{Key: "id", Value: "not-uuid"}}
+
+		var be *BindingError
+		require.ErrorAs(t, Bind(c, new(request)), &be)
+		require.Equal(t, "id", be.Field)
+		require.Equal(t, []string{"not-uuid"}, be.Values)
+	})
+	t.Run("collect errors", func(t *testing.T) {
+		t.Parallel()
+		c := newTestContext(t, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "id", Value: "not-uuid"}}
+
+		var ve ValidationErrors
+		require.ErrorAs(t, Bind(c, new(request), WithCollectErrors()), &ve)
+		require.Len(t, ve, 2)
+		require.Equal(t, "id", ve[0].Field)
+		require.Equal(t, "name", ve[1].Field)
+	})
+	t.Run("invalid destination", func(t *testing.T) {
+		t.Parallel()
+		c := newTestContext(t, http.MethodGet, "/", nil)
+		err := Bind(c, request{})
+		require.Error(t, err)
+		var be *BindingError
+		require.False(t, errors.As(err, &be))
+	})
+}
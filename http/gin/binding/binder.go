@@ -2,6 +2,7 @@ package binding
 
 import (
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"time"
@@ -21,11 +22,16 @@ type BindingError struct {
 	*HTTPError
 	// Values of parameter that failed to bind.
 	Values []string `json:"-"`
+	// Kind identifies error for translation, i.e. `required`. Empty for custom messages
+	Kind string `json:"-"`
+	// Params are substituted into translated message
+	Params map[string]any `json:"-"`
 }
 
-// NewBindingError creates a new instance of binding error
+// NewBindingError creates a new instance of binding error. Msg message is stored as Kind and Params
+// of error and rendered as English text.
 func NewBindingError(sourceParam string, values []string, message interface{}, internalError error) error {
-	return &BindingError{
+	be := &BindingError{
 		Field:  sourceParam,
 		Values: values,
 		HTTPError: &HTTPError{
@@ -34,23 +40,45 @@ func NewBindingError(sourceParam string, values []string, message interface{}, i
 			Internal: internalError,
 		},
 	}
+	if m, ok := message.(Msg); ok {
+		be.Kind, be.Params, be.Message = m.Kind, m.Params, m.String()
+	}
+	return be
 }
 
 // Error returns error message
 func (be *BindingError) Error() string {
+	if be.HTTPError == nil {
+		return fmt.Sprintf("code=%d, field=%s", http.StatusBadRequest, be.Field)
+	}
 	return fmt.Sprintf("%s, field=%s", be.HTTPError.Error(), be.Field)
 }
 
+// text returns message of error as text, empty when error has no HTTPError
+func (be *BindingError) text() string {
+	if be.HTTPError == nil || be.Message == nil {
+		return ""
+	}
+	return fmt.Sprint(be.Message)
+}
+
 type ValueBinder struct {
 	// ValueFunc is used to get a single parameter (first) value from a request
 	ValueFunc func(sourceParam string) string
 	// ValuesFunc is used to get all values for parameter from request. i.e. `/api/search?ids=1&ids=2`
 	ValuesFunc func(sourceParam string) []string
-	// ErrorFunc is used to create errors. Allows you to use your own error type, that for example marshals to your specific json response
+	// FilesFunc is used to get uploaded files for multipart form field. Only form field binders set it
+	FilesFunc func(sourceParam string) []*multipart.FileHeader
+	// ErrorFunc is used to create errors. Allows you to use your own error type, that for example marshals to your specific json response.
+	// Binders pass message as Msg, not string. Msg renders English text by fmt.Sprint or its String and MarshalText
+	// methods, its Kind and Params allow translation. Messages of custom rules may still be strings.
+	// Size and type limit failures set Code to 413 or 415 on returned BindingError or HTTPError.
 	ErrorFunc func(sourceParam string, values []string, message interface{}, internalError error) error
 	errors    []error
 	// failFast is a flag for binding methods to return without attempting to bind when previous binding already failed
 	failFast bool
+	// last is the most recently bound value that validation rules apply to
+	last *boundValue
 }
 
 // FormFieldBinder creates form field value binder
@@ -82,6 +110,7 @@ func FormFieldBinder(c *gin.Context) *ValueBinder {
 		}
 		return values
 	}
+	vb.FilesFunc = multipartFiles(c.Request)
 
 	return vb
 }
@@ -118,16 +147,72 @@ func PathParamsBinder(c *gin.Context) *ValueBinder {
 	}
 }
 
-// BindError returns first seen bind error and resets/empties binder errors for further calls
+// HeaderBinder creates request header value binder
+func HeaderBinder(c *gin.Context) *ValueBinder {
+	return &ValueBinder{
+		failFast:  true,
+		ValueFunc: c.GetHeader,
+		ValuesFunc: func(sourceParam string) []string {
+			values := c.Request.Header.Values(sourceParam)
+			if len(values) == 0 {
+				return nil
+			}
+			return values
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// CookieBinder creates cookie value binder
+func CookieBinder(c *gin.Context) *ValueBinder {
+	return &ValueBinder{
+		failFast:   true,
+		ValueFunc:  cookieValue(c.Request),
+		ValuesFunc: cookieValues(c.Request),
+		ErrorFunc:  NewBindingError,
+	}
+}
+
+// FailFast sets whether binding methods stop after first failed binding. Binders are created in fail fast mode,
+// disabling it makes BindError return ValidationErrors listing every failed field.
+func (b *ValueBinder) FailFast(value bool) *ValueBinder {
+	b.failFast = value
+	return b
+}
+
+// BindError returns first seen bind error and resets/empties binder errors for further calls.
+// When binder is not in fail fast mode all seen errors are returned as ValidationErrors.
 func (b *ValueBinder) BindError() error {
 	if b.errors == nil {
 		return nil
 	}
+	if !b.failFast {
+		return b.BindErrors()
+	}
 	err := b.errors[0]
 	b.errors = nil // reset errors so next chain will start from zero
 	return err
 }
 
+// BindErrors returns all seen bind errors as ValidationErrors and resets/empties binder errors for further calls
+func (b *ValueBinder) BindErrors() error {
+	if b.errors == nil {
+		return nil
+	}
+	errs := make(ValidationErrors, 0, len(b.errors))
+	for _, err := range b.errors {
+		errs.Add(err)
+	}
+	b.errors = nil // reset errors so next chain will start from zero
+	return errs
+}
+
+// bound records value being bound so that following rules apply to it and reports whether binding should be skipped
+func (b *ValueBinder) bound(sourceParam string, dest any) bool {
+	b.last = &boundValue{key: sourceParam, dest: dest, errors: len(b.errors)}
+	return b.failFast && b.errors != nil
+}
+
 func (b *ValueBinder) setError(err error) {
 	if b.errors == nil {
 		b.errors = []error{err}
@@ -145,21 +230,21 @@ func (b *ValueBinder) UUID(key string, dest *uuid.UUID) *ValueBinder {
 }
 
 func (b *ValueBinder) uuid(key string, dest *uuid.UUID, valueMustExist bool) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(key, dest) {
 		return b
 	}
 
 	value := b.ValueFunc(key)
 	if value == "" {
 		if valueMustExist {
-			b.setError(b.ErrorFunc(key, []string{value}, "required field value is empty", nil))
+			b.setError(b.ErrorFunc(key, []string{value}, msg(KindRequired), nil))
 		}
 		return b
 	}
 
 	id, err := uuid.FromString(value)
 	if err != nil {
-		b.setError(b.ErrorFunc(key, []string{value}, "invalid uuid value", nil))
+		b.setError(b.ErrorFunc(key, []string{value}, msg(KindInvalidUUID), nil))
 		return b
 	}
 	*dest = id
@@ -191,20 +276,20 @@ func (b *ValueBinder) TimeOnly(key string, dest *time.Time) *ValueBinder {
 }
 
 func (b *ValueBinder) time(key string, dest *time.Time, layout string, valueMustExist bool) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(key, dest) {
 		return b
 	}
 
 	value := b.ValueFunc(key)
 	if value == "" {
 		if valueMustExist {
-			b.setError(b.ErrorFunc(key, []string{value}, "required field value is empty", nil))
+			b.setError(b.ErrorFunc(key, []string{value}, msg(KindRequired), nil))
 		}
 		return b
 	}
 	t, err := time.Parse(layout, value)
 	if err != nil {
-		b.setError(b.ErrorFunc(key, []string{value}, "failed to bind field to value Time", err))
+		b.setError(b.ErrorFunc(key, []string{value}, msg(KindInvalidTime, "layout", layout), err))
 		return b
 	}
 	*dest = t
@@ -213,7 +298,7 @@ func (b *ValueBinder) time(key string, dest *time.Time, layout string, valueMust
 
 // ShouldString binds parameter to string variable
 func (b *ValueBinder) ShouldString(sourceParam string, dest *string) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, dest) {
 		return b
 	}
 
@@ -227,13 +312,13 @@ func (b *ValueBinder) ShouldString(sourceParam string, dest *string) *ValueBinde
 
 // String requires parameter value to exist to bind to string variable. Returns error when value does not exist
 func (b *ValueBinder) String(sourceParam string, dest *string) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, dest) {
 		return b
 	}
 
 	value := b.ValueFunc(sourceParam)
 	if value == "" {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
 		return b
 	}
 	*dest = value
@@ -251,14 +336,14 @@ func (b *ValueBinder) Bool(sourceParam string, dest *bool) *ValueBinder {
 }
 
 func (b *ValueBinder) boolValue(sourceParam string, dest *bool, valueMustExist bool) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, dest) {
 		return b
 	}
 
 	value := b.ValueFunc(sourceParam)
 	if value == "" {
 		if valueMustExist {
-			b.setError(b.ErrorFunc(sourceParam, []string{}, "required field value is empty", nil))
+			b.setError(b.ErrorFunc(sourceParam, []string{}, msg(KindRequired), nil))
 		}
 		return b
 	}
@@ -268,7 +353,7 @@ func (b *ValueBinder) boolValue(sourceParam string, dest *bool, valueMustExist b
 func (b *ValueBinder) bool(sourceParam string, value string, dest *bool) *ValueBinder {
 	n, err := strconv.ParseBool(value)
 	if err != nil {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to bool", err))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidBool), err))
 		return b
 	}
 
@@ -278,7 +363,7 @@ func (b *ValueBinder) bool(sourceParam string, value string, dest *bool) *ValueB
 
 // ShouldCustom binds parameter to IBindable variable
 func (b *ValueBinder) ShouldCustom(sourceParam string, dest IBindable) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, dest) {
 		return b
 	}
 
@@ -287,40 +372,40 @@ func (b *ValueBinder) ShouldCustom(sourceParam string, dest IBindable) *ValueBin
 		return b
 	}
 	if err := dest.Parse(value); err != nil {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to IBindable", err))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidValue), err))
 	}
 	return b
 }
 
 // Custom requires parameter value to exist to bind to IBindable variable. Returns error when value does not exist
 func (b *ValueBinder) Custom(sourceParam string, dest IBindable) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, dest) {
 		return b
 	}
 
 	value := b.ValueFunc(sourceParam)
 	if value == "" {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
 		return b
 	}
 	if err := dest.Parse(value); err != nil {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to IBindable", err))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidValue), err))
 	}
 	return b
 }
 
 func (b *ValueBinder) CustomFunc(sourceParam string, fn func(string) error) *ValueBinder {
-	if b.failFast && b.errors != nil {
+	if b.bound(sourceParam, fn) {
 		return b
 	}
 
 	value := b.ValueFunc(sourceParam)
 	if value == "" {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
 		return b
 	}
 	if err := fn(value); err != nil {
-		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to IBindable", err))
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidValue), err))
 	}
 	return b
 }
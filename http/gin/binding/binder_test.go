@@ -0,0 +1,145 @@
+package binding
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValueBinder_Numbers(t *testing.T) {
+	t.Parallel()
+	c := newTestContext(t, http.MethodGet, "/?i=-12&i8=127&u16=65535&f=1.5&d=1m30s", nil)
+
+	var (
+		i   int
+		i8  int8
+		u16 uint16
+		f   float64
+		d   time.Duration
+		opt int64
+	)
+	err := QueryParamsBinder(c).
+		Int("i", &i).
+		Int8("i8", &i8).
+		Uint16("u16", &u16).
+		Float64("f", &f).
+		Duration("d", &d).
+		ShouldInt64("missing", &opt).
+		BindError()
+	require.NoError(t, err)
+	require.Equal(t, -12, i)
+	require.Equal(t, int8(127), i8)
+	require.Equal(t, uint16(65535), u16)
+	require.Equal(t, 1.5, f)
+	require.Equal(t, 90*time.Second, d)
+	require.Zero(t, opt)
+}
+
+func Test_ValueBinder_NumbersInvalid(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		name  string
+		query string
+		bind  func(*ValueBinder) *ValueBinder
+	}{
+		{"overflow", "/?v=128", func(b *ValueBinder) *ValueBinder { return b.Int8("v", new(int8)) }},
+		{"negative uint", "/?v=-1", func(b *ValueBinder) *ValueBinder { return b.Uint("v", new(uint)) }},
+		{"not float", "/?v=abc", func(b *ValueBinder) *ValueBinder { return b.Float32("v", new(float32)) }},
+		{"not duration", "/?v=5", func(b *ValueBinder) *ValueBinder { return b.Duration("v", new(time.Duration)) }},
+		{"required", "/", func(b *ValueBinder) *ValueBinder { return b.Int("v", new(int)) }},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			c := newTestContext(t, http.MethodGet, p.query, nil)
+			var be *BindingError
+			require.ErrorAs(t, p.bind(QueryParamsBinder(c)).BindError(), &be)
+			require.Equal(t, "v", be.Field)
+		})
+	}
+}
+
+func Test_ValueBinder_Slices(t *testing.T) {
+	t.Parallel()
+	id1, id2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	c := newTestContext(t, http.MethodGet, "/?ids=1&ids=2,3&uuids="+id1.String()+","+id2.String()+"&tags=a,%20b&days=2024-01-01&days=2024-01-02", nil)
+
+	var (
+		ids   []int
+		uuids []uuid.UUID
+		tags  []string
+		days  []time.Time
+		none  []int
+	)
+	err := QueryParamsBinder(c).
+		Ints("ids", &ids).
+		UUIDs("uuids", &uuids).
+		Strings("tags", &tags).
+		Times("days", &days, time.DateOnly).
+		ShouldInts("none", &none).
+		BindError()
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, ids)
+	require.Equal(t, []uuid.UUID{id1, id2}, uuids)
+	require.Equal(t, []string{"a", "b"}, tags)
+	require.Len(t, days, 2)
+	require.Nil(t, none)
+
+	var be *BindingError
+	require.ErrorAs(t, QueryParamsBinder(c).Ints("tags", &ids).BindError(), &be)
+	require.Equal(t, []string{"a"}, be.Values)
+	require.ErrorAs(t, QueryParamsBinder(c).Ints("none", &ids).BindError(), &be)
+	require.Equal(t, "none", be.Field)
+}
+
+func Test_ValueBinder_TimesCommaLayout(t *testing.T) {
+	t.Parallel()
+	first, second := "Mon, 01 Jan 2024 10:00:00 UTC", "Tue, 02 Jan 2024 10:00:00 UTC"
+	c := newTestContext(t, http.MethodGet, "/?"+url.Values{"at": {first, second}}.Encode(), nil)
+
+	var at []time.Time
+	require.NoError(t, QueryParamsBinder(c).Times("at", &at, time.RFC1123).BindError())
+	require.Len(t, at, 2)
+	require.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), at[1].UTC())
+}
+
+func Test_ValueBinder_CollectErrors(t *testing.T) {
+	t.Parallel()
+	c := newTestContext(t, http.MethodGet, "/?age=abc&id=1", nil)
+
+	var (
+		age  int
+		id   uuid.UUID
+		name string
+	)
+	err := QueryParamsBinder(c).FailFast(false).
+		Int("age", &age).
+		UUID("id", &id).
+		String("name", &name).
+		BindError()
+
+	var ve ValidationErrors
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve, 3)
+	require.Equal(t, "age", ve[0].Field)
+	require.Equal(t, "id", ve[1].Field)
+	require.Equal(t, "name", ve[2].Field)
+
+	var be *BindingError
+	require.ErrorAs(t, err, &be)
+	require.Equal(t, "age", be.Field)
+
+	b, err := json.Marshal(ve)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"field":"age","values":["abc"],"message":"failed to bind field value to int"},
+		{"field":"id","values":["1"],"message":"invalid uuid value"},
+		{"field":"name","values":[""],"message":"required field value is empty"}
+	]`, string(b))
+}
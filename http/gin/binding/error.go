@@ -1,7 +1,10 @@
 package binding
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 type HTTPError struct {
@@ -17,3 +20,60 @@ func (he *HTTPError) Error() string {
 	}
 	return fmt.Sprintf("code=%d, message=%v, internal=%v", he.Code, he.Message, he.Internal)
 }
+
+// ValidationErrors lists every field that failed to bind when binder is not in fail fast mode
+type ValidationErrors []*BindingError
+
+type fieldErrorJSON struct {
+	Field   string   `json:"field"`
+	Values  []string `json:"values"`
+	Message string   `json:"message"`
+}
+
+// Add appends err to list. Nested ValidationErrors are flattened and errors that are not BindingError are wrapped into one
+func (ve *ValidationErrors) Add(err error) {
+	var other ValidationErrors
+	if errors.As(err, &other) {
+		*ve = append(*ve, other...)
+		return
+	}
+	var be *BindingError
+	if !errors.As(err, &be) {
+		be = NewBindingError("", nil, err.Error(), err).(*BindingError)
+	}
+	*ve = append(*ve, be)
+}
+
+// Error makes it compatible with the `error` interface.
+func (ve ValidationErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("code=400, message=validation failed, fields=")
+	for i, be := range ve {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(be.Field)
+	}
+	return sb.String()
+}
+
+// Unwrap allows errors.As to find individual BindingError in list
+func (ve ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(ve))
+	for i := range ve {
+		errs[i] = ve[i]
+	}
+	return errs
+}
+
+// MarshalJSON marshals errors as `[{"field":"","values":[],"message":""}]`
+func (ve ValidationErrors) MarshalJSON() ([]byte, error) {
+	list := make([]fieldErrorJSON, len(ve))
+	for i, be := range ve {
+		list[i] = fieldErrorJSON{Field: be.Field, Values: be.Values, Message: be.text()}
+		if list[i].Values == nil {
+			list[i].Values = []string{}
+		}
+	}
+	return json.Marshal(list)
+}
@@ -0,0 +1,189 @@
+package binding
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	ghttp "github.com/pudottapommin/golib/http"
+	"github.com/pudottapommin/golib/pkg/set"
+)
+
+type (
+	FileOptsFn func(*fileConfig)
+	fileConfig struct {
+		// maxFileSize is maximum size of single file in bytes, 0 means unlimited
+		//
+		// Optional, Default: 0
+		maxFileSize int64
+		// maxTotalSize is maximum size of all files bound by single call in bytes, 0 means unlimited
+		//
+		// Optional, Default: 0
+		maxTotalSize int64
+		// allowedTypes lists allowed media types, i.e. `image/png` or `image/*`. Empty list allows any type
+		//
+		// Optional, Default: nil
+		allowedTypes []string
+	}
+)
+
+// sniffLen is number of bytes http.DetectContentType considers
+const sniffLen = 512
+
+var (
+	// genericMimeTypes are sniffed types too generic to tell file type, extension is used to resolve them
+	genericMimeTypes = set.SetOf("application/octet-stream", "text/plain", "text/xml")
+	// sniffableMimeTypes are recognized by content sniffing, so extension alone can't claim them
+	sniffableMimeTypes = set.SetOf("image/gif", "image/png", "image/jpeg", "image/webp", "image/x-icon",
+		"image/bmp", "application/pdf", "application/wasm", "text/html")
+)
+
+func WithMaxFileSize(n int64) FileOptsFn {
+	return func(c *fileConfig) {
+		c.maxFileSize = n
+	}
+}
+
+func WithMaxTotalSize(n int64) FileOptsFn {
+	return func(c *fileConfig) {
+		c.maxTotalSize = n
+	}
+}
+
+func WithAllowedTypes(types ...string) FileOptsFn {
+	return func(c *fileConfig) {
+		c.allowedTypes = types
+	}
+}
+
+// ShouldFile binds first uploaded file of multipart form field
+func (b *ValueBinder) ShouldFile(sourceParam string, dest *multipart.FileHeader, opts ...FileOptsFn) *ValueBinder {
+	return b.files(sourceParam, dest, nil, false, opts)
+}
+
+// File requires multipart form field to contain uploaded file. Returns error when no file was uploaded
+func (b *ValueBinder) File(sourceParam string, dest *multipart.FileHeader, opts ...FileOptsFn) *ValueBinder {
+	return b.files(sourceParam, dest, nil, true, opts)
+}
+
+// ShouldFiles binds all uploaded files of multipart form field
+func (b *ValueBinder) ShouldFiles(sourceParam string, dest *[]*multipart.FileHeader, opts ...FileOptsFn) *ValueBinder {
+	return b.files(sourceParam, nil, dest, false, opts)
+}
+
+// Files requires multipart form field to contain at least one uploaded file. Returns error when no file was uploaded
+func (b *ValueBinder) Files(sourceParam string, dest *[]*multipart.FileHeader, opts ...FileOptsFn) *ValueBinder {
+	return b.files(sourceParam, nil, dest, true, opts)
+}
+
+func (b *ValueBinder) files(sourceParam string, dest *multipart.FileHeader, destAll *[]*multipart.FileHeader, valueMustExist bool, opts []FileOptsFn) *ValueBinder {
+	if b.bound(sourceParam, nil) {
+		return b
+	}
+
+	var files []*multipart.FileHeader
+	if b.FilesFunc != nil {
+		files = b.FilesFunc(sourceParam)
+	}
+	if len(files) == 0 {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{}, msg(KindFileRequired), nil))
+		}
+		return b
+	}
+	if dest != nil {
+		files = files[:1]
+	}
+
+	cfg := &fileConfig{}
+	for i := range opts {
+		opts[i](cfg)
+	}
+
+	var total int64
+	for _, fh := range files {
+		total += fh.Size
+		if cfg.maxFileSize > 0 && fh.Size > cfg.maxFileSize {
+			b.setError(withCode(b.ErrorFunc(sourceParam, []string{fh.Filename}, msg(KindFileTooLarge, "max", cfg.maxFileSize), nil), http.StatusRequestEntityTooLarge))
+			return b
+		}
+		if cfg.maxTotalSize > 0 && total > cfg.maxTotalSize {
+			b.setError(withCode(b.ErrorFunc(sourceParam, []string{fh.Filename}, msg(KindFilesTooLarge, "max", cfg.maxTotalSize), nil), http.StatusRequestEntityTooLarge))
+			return b
+		}
+		if len(cfg.allowedTypes) == 0 {
+			continue
+		}
+		mediaType, err := fileMediaType(fh)
+		if err != nil {
+			b.setError(b.ErrorFunc(sourceParam, []string{fh.Filename}, msg(KindFileRead), err))
+			return b
+		}
+		if !mediaTypeAllowed(mediaType, cfg.allowedTypes) {
+			b.setError(withCode(b.ErrorFunc(sourceParam, []string{fh.Filename}, msg(KindFileType, "type", mediaType), nil), http.StatusUnsupportedMediaType))
+			return b
+		}
+	}
+
+	if dest != nil {
+		*dest = *files[0]
+	} else {
+		*destAll = files
+	}
+	return b
+}
+
+// fileMediaType detects media type of uploaded file from its content. When content is too generic to tell
+// the type, type resolved from file extension is used instead.
+func fileMediaType(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	sniffed := baseMediaType(http.DetectContentType(buf[:n]))
+	if !genericMimeTypes.Contains(sniffed) {
+		return sniffed, nil
+	}
+	byExt := baseMediaType(ghttp.ResolveWellKnownMimeType(filepath.Ext(fh.Filename)))
+	if byExt == "" || sniffableMimeTypes.Contains(byExt) {
+		return sniffed, nil
+	}
+	return byExt, nil
+}
+
+func mediaTypeAllowed(mediaType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mediaType == a {
+			return true
+		}
+	}
+	return false
+}
+
+func baseMediaType(ct string) string {
+	if ct == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
+	}
+	return mediaType
+}
@@ -0,0 +1,112 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+func newMultipartRequest(t *testing.T, files map[string][][2]string) *http.Request {
+	t.Helper()
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for field, list := range files {
+		for _, f := range list {
+			w, err := mw.CreateFormFile(field, f[0])
+			require.NoError(t, err)
+			_, err = w.Write([]byte(f[1]))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func Test_ValueBinder_Files(t *testing.T) {
+	t.Parallel()
+	r := newMultipartRequest(t, map[string][][2]string{
+		"avatar": {{"me.png", string(testPNG)}},
+		"docs":   {{"a.txt", "hello"}, {"b.json", `{"a":1}`}},
+	})
+
+	var (
+		avatar  multipart.FileHeader
+		docs    []*multipart.FileHeader
+		missing []*multipart.FileHeader
+	)
+	err := RequestFormFieldBinder(r).
+		File("avatar", &avatar, WithAllowedTypes("image/*"), WithMaxFileSize(1024)).
+		Files("docs", &docs, WithAllowedTypes("text/plain", "application/json"), WithMaxTotalSize(64)).
+		ShouldFiles("missing", &missing).
+		BindError()
+	require.NoError(t, err)
+	require.Equal(t, "me.png", avatar.Filename)
+	require.Len(t, docs, 2)
+	require.Nil(t, missing)
+}
+
+func Test_ValueBinder_FilesErrors(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		name  string
+		files [][2]string
+		opts  []FileOptsFn
+		code  int
+	}{
+		{"missing", nil, nil, http.StatusBadRequest},
+		{"file size", [][2]string{{"a.txt", "hello world"}}, []FileOptsFn{WithMaxFileSize(5)}, http.StatusRequestEntityTooLarge},
+		{"total size", [][2]string{{"a.txt", "hello"}, {"b.txt", "world"}}, []FileOptsFn{WithMaxTotalSize(8)}, http.StatusRequestEntityTooLarge},
+		{"sniffed type", [][2]string{{"a.png", "<html><body></body></html>"}}, []FileOptsFn{WithAllowedTypes("image/png")}, http.StatusUnsupportedMediaType},
+		{"extension can't claim sniffable type", [][2]string{{"a.png", "plain text"}}, []FileOptsFn{WithAllowedTypes("image/png")}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			files := map[string][][2]string{"other": {{"x.txt", "x"}}}
+			if p.files != nil {
+				files["docs"] = p.files
+			}
+			var docs []*multipart.FileHeader
+			var be *BindingError
+			require.ErrorAs(t, RequestFormFieldBinder(newMultipartRequest(t, files)).Files("docs", &docs, p.opts...).BindError(), &be)
+			require.Equal(t, p.code, be.Code)
+			require.Equal(t, "docs", be.Field)
+			require.Nil(t, docs)
+		})
+	}
+}
+
+type testCustomError struct {
+	field   string
+	message any
+}
+
+func (e *testCustomError) Error() string {
+	return e.field
+}
+
+func testCustomErrorFunc(sourceParam string, _ []string, message any, _ error) error {
+	return &testCustomError{field: sourceParam, message: message}
+}
+
+func Test_ValueBinder_FilesCustomError(t *testing.T) {
+	t.Parallel()
+	r := newMultipartRequest(t, map[string][][2]string{"docs": {{"a.txt", "hello world"}}})
+	for _, opt := range []FileOptsFn{WithMaxFileSize(5), WithMaxTotalSize(5), WithAllowedTypes("image/png")} {
+		b := RequestFormFieldBinder(r)
+		b.ErrorFunc = testCustomErrorFunc
+		var ce *testCustomError
+		require.ErrorAs(t, b.Files("docs", new([]*multipart.FileHeader), opt).BindError(), &ce)
+		require.Equal(t, "docs", ce.field)
+	}
+}
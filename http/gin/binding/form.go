@@ -0,0 +1,335 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+)
+
+// defaultMaxFormIndex is highest slice index BindForm accepts unless configured otherwise
+const defaultMaxFormIndex = 100
+
+// formNode is a level of form keys, i.e. `items[0].name` is stored as items -> 0 -> name
+type formNode struct {
+	values   []string
+	children map[string]*formNode
+}
+
+type formDecoder struct {
+	cfg  *bindConfig
+	errs *ValidationErrors
+}
+
+// WithMaxIndex sets highest slice index accepted by BindForm. Higher indexes are reported as BindingError
+func WithMaxIndex(n int) BindOptsFn {
+	return func(c *bindConfig) {
+		c.maxIndex = n
+	}
+}
+
+// BindForm binds form fields into struct pointed to by dst. Unlike Bind it understands nested keys:
+// `address.street` binds into nested struct, `items[0].name` into slice of structs, `tags[]` into slice
+// and `meta[color]` or `meta.color` into map[string]T.
+//
+// Field names come from `form` tag, falling back to Go field name. Errors report full path of value,
+// i.e. `items[0].name`. Slice indexes are limited by WithMaxIndex (default 100). Errors are created by function
+// set by WithBindErrorFunc, NewBindingError by default.
+func BindForm(c *gin.Context, dst any, opts ...BindOptsFn) error {
+	return BindRequestForm(c.Request, dst, opts...)
+}
+
+// BindRequestForm binds form fields of net/http request into struct pointed to by dst. See BindForm.
+func BindRequestForm(r *http.Request, dst any, opts ...BindOptsFn) error {
+	cfg := &bindConfig{maxIndex: defaultMaxFormIndex, errorFunc: NewBindingError}
+	for i := range opts {
+		opts[i](cfg)
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: dst must be non-nil pointer to struct, got %T", dst)
+	}
+	if r.Form == nil {
+		// this is same as `Request().FormValue()` does internally
+		_ = r.ParseMultipartForm(32 << 20)
+	}
+
+	d := &formDecoder{cfg: cfg}
+	if cfg.collectErrors {
+		d.errs = &ValidationErrors{}
+	}
+	if err := d.decodeStruct(v.Elem(), newFormTree(r.Form), ""); err != nil {
+		return err
+	}
+	if d.errs != nil && len(*d.errs) > 0 {
+		return *d.errs
+	}
+	return nil
+}
+
+func newFormTree(form url.Values) *formNode {
+	root := &formNode{}
+	for key, values := range form {
+		segments, ok := parseFormKey(key)
+		if !ok {
+			continue
+		}
+		n := root
+		for _, s := range segments {
+			if n.children == nil {
+				n.children = make(map[string]*formNode)
+			}
+			child, ok := n.children[s]
+			if !ok {
+				child = &formNode{}
+				n.children[s] = child
+			}
+			n = child
+		}
+		n.values = append(n.values, values...)
+	}
+	return root
+}
+
+// parseFormKey splits key like `items[0].name` or `tags[]` into segments. Trailing `[]` is dropped
+func parseFormKey(key string) ([]string, bool) {
+	var segments []string
+	for key != "" {
+		switch key[0] {
+		case '.':
+			key = key[1:]
+		case '[':
+			end := strings.IndexByte(key, ']')
+			if end < 0 {
+				return nil, false
+			}
+			if end == 1 {
+				// `tags[]` only marks multiple values
+				if len(key) > 2 {
+					return nil, false
+				}
+				return segments, len(segments) > 0
+			}
+			segments = append(segments, key[1:end])
+			key = key[end+1:]
+			continue
+		}
+		end := strings.IndexAny(key, ".[")
+		if end < 0 {
+			end = len(key)
+		}
+		if end == 0 {
+			return nil, false
+		}
+		segments = append(segments, key[:end])
+		key = key[end:]
+	}
+	return segments, len(segments) > 0
+}
+
+func (d *formDecoder) decodeStruct(v reflect.Value, n *formNode, path string) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		// embedded structs of unexported type still promote their exported fields
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tag, tagged := sf.Tag.Lookup("form")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if !tagged && sf.Anonymous && isNestedStruct(v.Field(i)) {
+			if err := d.decodeStruct(v.Field(i), n, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		var child *formNode
+		if n != nil {
+			child = n.children[name]
+		}
+		if err := d.decode(v.Field(i), child, joinFormPath(path, name), sf, opts == "required"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *formDecoder) decode(v reflect.Value, n *formNode, path string, sf reflect.StructField, required bool) error {
+	if n == nil {
+		if required {
+			return d.fail(d.cfg.errorFunc(path, []string{""}, msg(KindRequired), nil))
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem(), n, path, sf, required)
+	}
+
+	switch {
+	case isFormLeaf(v.Type()):
+		if len(n.children) > 0 && len(n.values) == 0 {
+			return d.fail(d.cfg.errorFunc(path, nil, msg(KindNestedNotAllowed), nil))
+		}
+		return d.fail(bindValue(d.leafBinder(n.values), path, v, sf, required))
+	case v.Kind() == reflect.Struct:
+		return d.decodeStruct(v, n, path)
+	case v.Kind() == reflect.Map:
+		return d.decodeMap(v, n, path, sf)
+	case v.Kind() == reflect.Slice:
+		return d.decodeSlice(v, n, path, sf)
+	}
+	return fmt.Errorf("%w %s of %s", ErrUnsupportedType, v.Type(), sf.Name)
+}
+
+func (d *formDecoder) decodeMap(v reflect.Value, n *formNode, path string, sf reflect.StructField) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("%w %s of %s", ErrUnsupportedType, t, sf.Name)
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(n.children)))
+	}
+	for key, child := range n.children {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(elem, child, joinFormPath(path, key), sf, false); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+	}
+	return nil
+}
+
+func (d *formDecoder) decodeSlice(v reflect.Value, n *formNode, path string, sf reflect.StructField) error {
+	// `tags=a&tags=b` or `tags[]=a,b` for slices natively supported by ValueBinder
+	if len(n.children) == 0 && isBinderSlice(v.Type()) {
+		return d.fail(bindValue(d.leafBinder(n.values), path, v, sf, false))
+	}
+
+	elemType := v.Type().Elem()
+	if len(n.children) == 0 {
+		if !isFormLeaf(elemType) {
+			return nil
+		}
+		if len(n.values) > d.cfg.maxIndex+1 {
+			return d.fail(d.cfg.errorFunc(path, nil, msg(KindTooManyValues, "max", d.cfg.maxIndex+1), nil))
+		}
+		s := reflect.MakeSlice(v.Type(), len(n.values), len(n.values))
+		for i, value := range n.values {
+			if err := d.decode(s.Index(i), &formNode{values: []string{value}}, indexFormPath(path, i), sf, false); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	length := 0
+	indexes := make(map[int]*formNode, len(n.children))
+	for key, child := range n.children {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			if err = d.fail(d.cfg.errorFunc(joinFormPath(path, key), nil, msg(KindInvalidIndex), err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if i > d.cfg.maxIndex {
+			if err = d.fail(d.cfg.errorFunc(indexFormPath(path, i), nil, msg(KindIndexTooLarge, "max", d.cfg.maxIndex), nil)); err != nil {
+				return err
+			}
+			continue
+		}
+		indexes[i] = child
+		length = max(length, i+1)
+	}
+
+	s := reflect.MakeSlice(v.Type(), length, length)
+	for i := range length {
+		child, ok := indexes[i]
+		if !ok {
+			continue
+		}
+		if err := d.decode(s.Index(i), child, indexFormPath(path, i), sf, false); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+// fail returns err, unless errors are being collected and err was caused by request values
+func (d *formDecoder) fail(err error) error {
+	if err == nil || d.errs == nil || errors.Is(err, ErrUnsupportedType) {
+		return err
+	}
+	d.errs.Add(err)
+	return nil
+}
+
+// leafBinder returns binder of values of single form key
+func (d *formDecoder) leafBinder(values []string) *ValueBinder {
+	var value string
+	if len(values) > 0 {
+		value = values[0]
+	}
+	return &ValueBinder{
+		failFast:   true,
+		ValueFunc:  func(string) string { return value },
+		ValuesFunc: func(string) []string { return values },
+		ErrorFunc:  d.cfg.errorFunc,
+	}
+}
+
+// isFormLeaf reports whether type is bound from single value
+func isFormLeaf(t reflect.Type) bool {
+	if t == uuidType || t == timeType || reflect.PointerTo(t).Implements(bindableType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isBinderSlice(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeFor[[]string](), reflect.TypeFor[[]int](), reflect.TypeFor[[]uuid.UUID](), reflect.TypeFor[[]time.Time]():
+		return true
+	}
+	return false
+}
+
+func joinFormPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexFormPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
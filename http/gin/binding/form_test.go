@@ -0,0 +1,128 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	Street string `form:"street,required"`
+	Zip    int    `form:"zip"`
+}
+
+type testProfile struct {
+	Name    string            `form:"name"`
+	Address testAddress       `form:"address"`
+	Billing *testAddress      `form:"billing"`
+	Items   []testItem        `form:"items"`
+	Tags    []string          `form:"tags"`
+	Scores  []int8            `form:"scores"`
+	Meta    map[string]string `form:"meta"`
+	Stock   map[string]int    `form:"stock"`
+}
+
+type testItem struct {
+	Name string `form:"name"`
+	Qty  int    `form:"qty"`
+}
+
+func newFormRequest(t *testing.T, form url.Values) *http.Request {
+	t.Helper()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func Test_BindForm(t *testing.T) {
+	t.Parallel()
+	r := newFormRequest(t, url.Values{
+		"name":           {"John"},
+		"address.street": {"Main"},
+		"address[zip]":   {"12345"},
+		"items[0].name":  {"apple"},
+		"items[0].qty":   {"2"},
+		"items[2][name]": {"pear"},
+		"tags[]":         {"a", "b"},
+		"scores":         {"1", "2"},
+		"meta[color]":    {"red"},
+		"meta.size":      {"L"},
+		"stock[apple]":   {"7"},
+	})
+
+	var dst testProfile
+	require.NoError(t, BindRequestForm(r, &dst))
+	require.Equal(t, "John", dst.Name)
+	require.Equal(t, testAddress{Street: "Main", Zip: 12345}, dst.Address)
+	require.Nil(t, dst.Billing)
+	require.Equal(t, []testItem{{Name: "apple", Qty: 2}, {}, {Name: "pear"}}, dst.Items)
+	require.Equal(t, []string{"a", "b"}, dst.Tags)
+	require.Equal(t, []int8{1, 2}, dst.Scores)
+	require.Equal(t, map[string]string{"color": "red", "size": "L"}, dst.Meta)
+	require.Equal(t, map[string]int{"apple": 7}, dst.Stock)
+}
+
+func Test_BindForm_Errors(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		name  string
+		form  url.Values
+		opts  []BindOptsFn
+		field string
+	}{
+		{"nested value", url.Values{"address.street": {"a"}, "address.zip": {"x"}}, nil, "address.zip"},
+		{"indexed value", url.Values{"address.street": {"a"}, "items[1].qty": {"x"}}, nil, "items[1].qty"},
+		{"map value", url.Values{"address.street": {"a"}, "stock[apple]": {"x"}}, nil, "stock.apple"},
+		{"slice value", url.Values{"address.street": {"a"}, "scores": {"1", "300"}}, nil, "scores[1]"},
+		{"required nested", url.Values{"billing.zip": {"1"}, "address.street": {"a"}}, nil, "billing.street"},
+		{"index limit", url.Values{"address.street": {"a"}, "items[11].name": {"x"}}, []BindOptsFn{WithMaxIndex(10)}, "items[11]"},
+		{"default index limit", url.Values{"address.street": {"a"}, "items[1000000].name": {"x"}}, nil, "items[1000000]"},
+		{"invalid index", url.Values{"address.street": {"a"}, "items[x].name": {"x"}}, nil, "items.x"},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			var be *BindingError
+			require.ErrorAs(t, BindRequestForm(newFormRequest(t, p.form), new(testProfile), p.opts...), &be)
+			require.Equal(t, p.field, be.Field)
+		})
+	}
+}
+
+func Test_BindForm_CustomError(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		form  url.Values
+		field string
+	}{
+		{url.Values{"address.street": {"a"}, "address.zip": {"x"}}, "address.zip"},
+		{url.Values{"billing.zip": {"1"}, "address.street": {"a"}}, "billing.street"},
+		{url.Values{"address.street": {"a"}, "items[1000000].name": {"x"}}, "items[1000000]"},
+		{url.Values{"address.street": {"a"}, "items[x].name": {"x"}}, "items.x"},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.field, func(t *testing.T) {
+			t.Parallel()
+			var ce *testCustomError
+			require.ErrorAs(t, BindRequestForm(newFormRequest(t, p.form), new(testProfile), WithBindErrorFunc(testCustomErrorFunc)), &ce)
+			require.Equal(t, p.field, ce.field)
+		})
+	}
+}
+
+func Test_BindForm_CollectErrors(t *testing.T) {
+	t.Parallel()
+	r := newFormRequest(t, url.Values{"address.zip": {"x"}, "items[0].qty": {"y"}})
+
+	var ve ValidationErrors
+	require.ErrorAs(t, BindRequestForm(r, new(testProfile), WithCollectErrors()), &ve)
+	require.Len(t, ve, 3)
+	fields := []string{ve[0].Field, ve[1].Field, ve[2].Field}
+	require.ElementsMatch(t, []string{"address.street", "address.zip", "items[0].qty"}, fields)
+}
@@ -0,0 +1,338 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ghttp "github.com/pudottapommin/golib/http"
+)
+
+type (
+	JSONOptsFn func(*jsonConfig)
+	jsonConfig struct {
+		// maxBodySize is maximum number of bytes read from request body
+		//
+		// Optional, Default: 1MB
+		maxBodySize int64
+		// disallowUnknownFields rejects objects with keys not matching any destination field
+		//
+		// Optional, Default: false
+		disallowUnknownFields bool
+		// checkContentType requires Content-Type to be application/json or any +json type
+		//
+		// Optional, Default: true
+		checkContentType bool
+		// errorFunc creates errors, see ValueBinder.ErrorFunc
+		//
+		// Optional, Default: NewBindingError
+		errorFunc func(sourceParam string, values []string, message interface{}, internalError error) error
+	}
+)
+
+const defaultMaxJSONBodySize = 1 << 20
+
+func WithMaxBodySize(n int64) JSONOptsFn {
+	return func(c *jsonConfig) {
+		c.maxBodySize = n
+	}
+}
+
+func WithDisallowUnknownFields() JSONOptsFn {
+	return func(c *jsonConfig) {
+		c.disallowUnknownFields = true
+	}
+}
+
+func WithContentTypeCheck(value bool) JSONOptsFn {
+	return func(c *jsonConfig) {
+		c.checkContentType = value
+	}
+}
+
+// WithErrorFunc sets function creating binding errors, like ValueBinder.ErrorFunc
+func WithErrorFunc(fn func(sourceParam string, values []string, message interface{}, internalError error) error) JSONOptsFn {
+	return func(c *jsonConfig) {
+		c.errorFunc = fn
+	}
+}
+
+// BindJSON decodes JSON request body into dst. Body must contain exactly one JSON value.
+//
+// Failures are returned as *BindingError with Field set to JSON pointer of offending value (i.e. `/items/3/price`)
+// and Code set to 400, 413 when body exceeds maximum size or 415 when Content-Type is not JSON.
+func BindJSON(c *gin.Context, dst any, opts ...JSONOptsFn) error {
+	return BindRequestJSON(c.Request, dst, opts...)
+}
+
+// BindRequestJSON decodes JSON body of net/http request into dst. See BindJSON.
+func BindRequestJSON(r *http.Request, dst any, opts ...JSONOptsFn) error {
+	cfg := &jsonConfig{maxBodySize: defaultMaxJSONBodySize, checkContentType: true, errorFunc: NewBindingError}
+	for i := range opts {
+		opts[i](cfg)
+	}
+
+	if cfg.checkContentType {
+		ct := r.Header.Get(ghttp.HeaderContentType)
+		if !isJSONContentType(ct) {
+			return withCode(cfg.errorFunc("", []string{ct}, msg(KindUnsupportedContentType), nil), http.StatusUnsupportedMediaType)
+		}
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return cfg.errorFunc("", nil, msg(KindBodyEmpty), nil)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, cfg.maxBodySize+1))
+	if err != nil {
+		return cfg.errorFunc("", nil, msg(KindBodyRead), err)
+	}
+	if int64(len(data)) > cfg.maxBodySize {
+		return withCode(cfg.errorFunc("", nil, msg(KindBodyTooLarge), nil), http.StatusRequestEntityTooLarge)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if cfg.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err = dec.Decode(dst); err != nil {
+		return jsonBindingError(cfg, data, dst, err)
+	}
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return cfg.errorFunc("", nil, msg(KindTrailingData), err)
+	}
+	return nil
+}
+
+func jsonBindingError(cfg *jsonConfig, data []byte, dst any, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return cfg.errorFunc("", nil, msg(KindBodyEmpty), err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return cfg.errorFunc("", nil, msg(KindUnexpectedEOF), err)
+	case errors.As(err, &syntaxErr):
+		return cfg.errorFunc(jsonPointerAt(data, syntaxErr.Offset), nil, msg(KindMalformedJSON, "offset", syntaxErr.Offset), err)
+	case errors.As(err, &typeErr):
+		field := jsonPointerAt(data, typeErr.Offset)
+		if field == "" && typeErr.Field != "" {
+			field = "/" + strings.ReplaceAll(typeErr.Field, ".", "/")
+		}
+		return cfg.errorFunc(field, nil, msg(KindInvalidJSONType, "value", typeErr.Value, "type", typeErr.Type), err)
+	}
+
+	// decoder does not export error type for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, uErr := strconv.Unquote(name); uErr == nil {
+			name = unquoted
+		}
+		field, ok := unknownFieldPointer(data, reflect.TypeOf(dst), name)
+		if !ok {
+			field = escapeJSONPointer(name)
+		}
+		return cfg.errorFunc(field, nil, msg(KindUnknownField), err)
+	}
+	return cfg.errorFunc("", nil, msg(KindInvalidJSON), err)
+}
+
+type jsonFrame struct {
+	array   bool
+	index   int
+	key     string
+	wantKey bool
+}
+
+// jsonPointerAt returns JSON pointer of value that ends at or contains given offset of data
+func jsonPointerAt(data []byte, offset int64) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []*jsonFrame
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return jsonPointer(stack)
+		}
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if top != nil && !top.array && top.wantKey {
+			if key, ok := tok.(string); ok {
+				top.key = key
+				top.wantKey = false
+				continue
+			}
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			if dec.InputOffset() >= offset {
+				return jsonPointer(stack)
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].wantKey = true
+			}
+			continue
+		}
+
+		if top != nil && top.array {
+			top.index++
+		}
+		if dec.InputOffset() >= offset {
+			return jsonPointer(stack)
+		}
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, &jsonFrame{array: d == '[', index: -1, wantKey: d == '{'})
+			continue
+		}
+		if top != nil {
+			top.wantKey = true
+		}
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// unknownFieldPointer returns JSON pointer of first object key name of data, which has no field in struct type it
+// decodes into. Decoder rejects first unknown key in document order, so walking data along type of dst finds it.
+func unknownFieldPointer(data []byte, t reflect.Type, name string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	pointer, found, _ := walkUnknownField(dec, t, "", name)
+	return pointer, found
+}
+
+// walkUnknownField consumes next value of decoder, t is type value decodes into, nil for values decoded
+// without field checks
+func walkUnknownField(dec *json.Decoder, t reflect.Type, path, name string) (string, bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", false, err
+	}
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		t = nil
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return "", false, nil
+	}
+
+	for i := 0; dec.More(); i++ {
+		var (
+			elem     reflect.Type
+			elemPath string
+		)
+		if d == '[' {
+			elemPath = path + "/" + strconv.Itoa(i)
+			if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				elem = t.Elem()
+			}
+		} else {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return "", false, err
+			}
+			key, _ := keyTok.(string)
+			elemPath = path + escapeJSONPointer(key)
+			if t != nil && t.Kind() == reflect.Struct {
+				f, ok := jsonField(t, key)
+				if !ok && key == name {
+					return elemPath, true, nil
+				}
+				elem = f
+			} else if t != nil && t.Kind() == reflect.Map {
+				elem = t.Elem()
+			}
+		}
+		if pointer, found, err := walkUnknownField(dec, elem, elemPath, name); found || err != nil {
+			return pointer, found, err
+		}
+	}
+	_, err = dec.Token()
+	return "", false, err
+}
+
+// jsonField returns type of struct field key decodes into, matching json tags and promoted fields of embedded
+// structs case-insensitively like encoding/json
+func jsonField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		tagName, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tagName == "" && ft.Kind() == reflect.Struct {
+			if et, ok := jsonField(ft, key); ok {
+				return et, true
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if tagName == "" {
+			tagName = f.Name
+		}
+		if strings.EqualFold(tagName, key) {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+func jsonPointer(stack []*jsonFrame) string {
+	var sb strings.Builder
+	for _, f := range stack {
+		switch {
+		case f.array && f.index >= 0:
+			sb.WriteByte('/')
+			sb.WriteString(strconv.Itoa(f.index))
+		case !f.array && !f.wantKey:
+			sb.WriteString(escapeJSONPointer(f.key))
+		}
+	}
+	return sb.String()
+}
+
+func escapeJSONPointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return "/" + strings.ReplaceAll(s, "/", "~1")
+}
+
+func isJSONContentType(ct string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mediaType == gin.MIMEJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// withCode sets status code of BindingError or HTTPError created by ErrorFunc, custom error types are returned as is
+func withCode(err error, code int) error {
+	var (
+		be *BindingError
+		he *HTTPError
+	)
+	if errors.As(err, &be) && be.HTTPError != nil {
+		be.Code = code
+	} else if errors.As(err, &he) {
+		he.Code = code
+	}
+	return err
+}
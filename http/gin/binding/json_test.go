@@ -0,0 +1,102 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testOrder struct {
+	Name  string `json:"name"`
+	Items []struct {
+		SKU   string  `json:"sku"`
+		Price float64 `json:"price"`
+	} `json:"items"`
+}
+
+func newJSONRequest(t *testing.T, contentType, body string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func Test_BindJSON(t *testing.T) {
+	t.Parallel()
+	c := newTestContext(t, http.MethodPost, "/", nil)
+	c.Request = newJSONRequest(t, "application/json; charset=utf-8", `{"name":"a","items":[{"sku":"x","price":1.5}]}`)
+
+	var dst testOrder
+	require.NoError(t, BindJSON(c, &dst))
+	require.Equal(t, "a", dst.Name)
+	require.Len(t, dst.Items, 1)
+	require.Equal(t, 1.5, dst.Items[0].Price)
+}
+
+func Test_BindJSON_Errors(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        []JSONOptsFn
+		code        int
+		field       string
+	}{
+		{"type mismatch", "application/json", `{"items":[{},{},{},{"sku":"x","price":"1"}]}`, nil, http.StatusBadRequest, "/items/3/price"},
+		{"type mismatch object", "application/json", `{"name":{"a":1},"items":[]}`, nil, http.StatusBadRequest, "/name"},
+		{"syntax", "application/json", `{"name":"a",}`, nil, http.StatusBadRequest, ""},
+		{"unexpected eof", "application/json", `{"name":`, nil, http.StatusBadRequest, ""},
+		{"empty", "application/json", ``, nil, http.StatusBadRequest, ""},
+		{"trailing", "application/json", `{"name":"a"} x`, nil, http.StatusBadRequest, ""},
+		{"second value", "application/json", `{"name":"a"}{"name":"b"}`, nil, http.StatusBadRequest, ""},
+		{"unknown field", "application/json", `{"name":"a","extra":1}`, []JSONOptsFn{WithDisallowUnknownFields()}, http.StatusBadRequest, "/extra"},
+		{"unknown nested field", "application/json", `{"name":"a","items":[{"sku":"x"},{"bogus":1}]}`, []JSONOptsFn{WithDisallowUnknownFields()}, http.StatusBadRequest, "/items/1/bogus"},
+		{"unknown field known at other level", "application/json", `{"items":[{"SKU":"x","name":"y"}],"name":"a"}`, []JSONOptsFn{WithDisallowUnknownFields()}, http.StatusBadRequest, "/items/0/name"},
+		{"too large", "application/json", `{"name":"abcdefghijklmnop"}`, []JSONOptsFn{WithMaxBodySize(10)}, http.StatusRequestEntityTooLarge, ""},
+		{"content type", "text/plain", `{}`, nil, http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			var be *BindingError
+			require.ErrorAs(t, BindRequestJSON(newJSONRequest(t, p.contentType, p.body), new(testOrder), p.opts...), &be)
+			require.Equal(t, p.code, be.Code)
+			require.Equal(t, p.field, be.Field)
+		})
+	}
+}
+
+func Test_BindJSON_Options(t *testing.T) {
+	t.Parallel()
+	require.NoError(t, BindRequestJSON(newJSONRequest(t, "text/plain", `{"name":"a","extra":1}`), new(testOrder), WithContentTypeCheck(false)))
+	require.NoError(t, BindRequestJSON(newJSONRequest(t, "application/problem+json", `{"name":"a"}  `), new(testOrder)))
+}
+
+func Test_BindJSON_CustomError(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        []JSONOptsFn
+		kind        string
+	}{
+		{"content type", "text/plain", `{}`, nil, KindUnsupportedContentType},
+		{"too large", "application/json", `{"name":"abcdefghijklmnop"}`, []JSONOptsFn{WithMaxBodySize(10)}, KindBodyTooLarge},
+		{"type mismatch", "application/json", `{"name":1}`, nil, KindInvalidJSONType},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			var ce *testCustomError
+			opts := append([]JSONOptsFn{WithErrorFunc(testCustomErrorFunc)}, p.opts...)
+			require.ErrorAs(t, BindRequestJSON(newJSONRequest(t, p.contentType, p.body), new(testOrder), opts...), &ce)
+			require.Equal(t, p.kind, ce.message.(Msg).Kind)
+		})
+	}
+}
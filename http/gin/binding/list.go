@@ -0,0 +1,314 @@
+package binding
+
+import (
+	"cmp"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pudottapommin/golib/pkg/set"
+)
+
+type (
+	SortDirection  uint8
+	FilterOperator string
+
+	SortField struct {
+		Field     string
+		Direction SortDirection
+	}
+	Filter struct {
+		Field    string
+		Operator FilterOperator
+		// Values holds single value for all operators except FilterIn
+		Values []string
+	}
+	// ListQuery is parsed pagination, sorting and filtering of list endpoint
+	ListQuery struct {
+		// Page is 1-based page number, 0 when Cursor is used
+		Page  int
+		Limit int
+		// Cursor is opaque position for cursor based pagination
+		Cursor  string
+		Sort    []SortField
+		Filters []Filter
+	}
+
+	ListOptsFn func(*listConfig)
+	listConfig struct {
+		// Optional, Default: 20
+		defaultLimit int
+		// Optional, Default: 100
+		maxLimit int
+		// Optional, Default: math.MaxInt32
+		maxPage int
+		// sortable lists fields allowed in sort, nothing is sortable by default
+		sortable set.Set[string]
+		// filterable lists fields allowed in filters with allowed operators, nothing is filterable by default
+		filterable map[string]set.Set[FilterOperator]
+		// Optional, Default: nil
+		defaultSort []SortField
+	}
+)
+
+const (
+	SortAsc SortDirection = iota
+	SortDesc
+)
+
+const (
+	FilterEq   FilterOperator = "eq"
+	FilterIn   FilterOperator = "in"
+	FilterGt   FilterOperator = "gt"
+	FilterLt   FilterOperator = "lt"
+	FilterLike FilterOperator = "like"
+)
+
+const (
+	listParamPage    = "page"
+	listParamLimit   = "per_page"
+	listParamCursor  = "cursor"
+	listParamSort    = "sort"
+	listParamFilter  = "filter"
+	listDefaultLimit = 20
+	listMaxLimit     = 100
+	listMaxPage      = math.MaxInt32
+)
+
+var filterOperators = set.SetOf(FilterEq, FilterIn, FilterGt, FilterLt, FilterLike)
+
+func WithDefaultLimit(n int) ListOptsFn {
+	return func(c *listConfig) {
+		c.defaultLimit = n
+	}
+}
+
+func WithMaxLimit(n int) ListOptsFn {
+	return func(c *listConfig) {
+		c.maxLimit = n
+	}
+}
+
+// WithMaxPage limits `page` parameter, so that offset of page stays within range of database
+func WithMaxPage(n int) ListOptsFn {
+	return func(c *listConfig) {
+		c.maxPage = n
+	}
+}
+
+// WithSortable allows fields to be used in `sort` parameter
+func WithSortable(fields ...string) ListOptsFn {
+	return func(c *listConfig) {
+		c.sortable.AddMultiple(fields...)
+	}
+}
+
+// WithFilterable allows field to be filtered with given operators. No operators means all operators are allowed
+func WithFilterable(field string, operators ...FilterOperator) ListOptsFn {
+	return func(c *listConfig) {
+		if len(operators) == 0 {
+			c.filterable[field] = filterOperators.Clone()
+			return
+		}
+		c.filterable[field] = set.SetOf(operators...)
+	}
+}
+
+// WithDefaultSort sets sort used when request has no `sort` parameter or it is empty
+func WithDefaultSort(fields ...SortField) ListOptsFn {
+	return func(c *listConfig) {
+		c.defaultSort = fields
+	}
+}
+
+// ParseListQuery parses `?page=2&per_page=50&sort=-created_at,name&filter[status]=open` style list parameters.
+// Cursor pagination uses `cursor` instead of `page`. Filter operator is given as `filter[amount][gt]=10`,
+// without operator FilterEq is used. FilterIn takes comma separated values.
+//
+// Only fields allowed by WithSortable and WithFilterable are accepted, violations are returned as *BindingError.
+func ParseListQuery(c *gin.Context, opts ...ListOptsFn) (*ListQuery, error) {
+	return ParseRequestListQuery(c.Request, opts...)
+}
+
+// ParseRequestListQuery parses list parameters of net/http request. See ParseListQuery.
+func ParseRequestListQuery(r *http.Request, opts ...ListOptsFn) (*ListQuery, error) {
+	cfg := &listConfig{
+		defaultLimit: listDefaultLimit,
+		maxLimit:     listMaxLimit,
+		maxPage:      listMaxPage,
+		sortable:     set.Set[string]{},
+		filterable:   map[string]set.Set[FilterOperator]{},
+	}
+	for i := range opts {
+		opts[i](cfg)
+	}
+
+	query := r.URL.Query()
+	q := &ListQuery{Limit: cfg.defaultLimit, Sort: cfg.defaultSort}
+	err := RequestQueryParamsBinder(r).
+		ShouldInt(listParamPage, &q.Page).Min(1).Max(float64(cfg.maxPage)).
+		ShouldInt(listParamLimit, &q.Limit).Min(1).Max(float64(cfg.maxLimit)).
+		ShouldString(listParamCursor, &q.Cursor).
+		BindError()
+	if err != nil {
+		return nil, err
+	}
+	if q.Cursor != "" && q.Page != 0 {
+		return nil, NewBindingError(listParamCursor, []string{q.Cursor}, msg(KindCursorWithPage), nil)
+	}
+	if q.Cursor == "" && q.Page == 0 {
+		q.Page = 1
+	}
+
+	if values := splitValues(query[listParamSort]); len(values) > 0 {
+		if q.Sort, err = parseSort(values, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if q.Filters, err = parseFilters(query, cfg); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func parseSort(values []string, cfg *listConfig) ([]SortField, error) {
+	fields := make([]SortField, 0, len(values))
+	for _, value := range values {
+		sf := SortField{Field: value}
+		if name, ok := strings.CutPrefix(value, "-"); ok {
+			sf = SortField{Field: name, Direction: SortDesc}
+		} else if name, ok = strings.CutPrefix(value, "+"); ok {
+			sf.Field = name
+		}
+		if !cfg.sortable.Contains(sf.Field) {
+			return nil, NewBindingError(listParamSort, []string{value}, msg(KindNotSortable, "field", sf.Field), nil)
+		}
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
+
+func parseFilters(query url.Values, cfg *listConfig) ([]Filter, error) {
+	var filters []Filter
+	for key, values := range query {
+		segments, ok := parseFormKey(key)
+		if !ok || segments[0] != listParamFilter {
+			continue
+		}
+		if len(segments) < 2 || len(segments) > 3 {
+			return nil, NewBindingError(key, values, msg(KindInvalidFilter), nil)
+		}
+
+		f := Filter{Field: segments[1], Operator: FilterEq}
+		if len(segments) == 3 {
+			f.Operator = FilterOperator(segments[2])
+		}
+		allowed, ok := cfg.filterable[f.Field]
+		if !ok {
+			return nil, NewBindingError(key, values, msg(KindNotFilterable, "field", f.Field), nil)
+		}
+		if !filterOperators.Contains(f.Operator) || !allowed.Contains(f.Operator) {
+			return nil, NewBindingError(key, values, msg(KindOperatorNotAllowed, "operator", f.Operator), nil)
+		}
+
+		if f.Operator == FilterIn {
+			f.Values = splitValues(values)
+		} else if len(values) > 0 && values[0] != "" {
+			f.Values = values[:1]
+		}
+		if len(f.Values) == 0 {
+			return nil, NewBindingError(key, values, msg(KindRequired), nil)
+		}
+		filters = append(filters, f)
+	}
+	slices.SortFunc(filters, func(a, b Filter) int {
+		return cmp.Or(cmp.Compare(a.Field, b.Field), cmp.Compare(a.Operator, b.Operator))
+	})
+	return filters, nil
+}
+
+// Offset returns number of items preceding current page, math.MaxInt when it doesn't fit int
+func (q *ListQuery) Offset() int {
+	if q.Page < 1 || q.Limit < 1 {
+		return 0
+	}
+	if q.Page-1 > math.MaxInt/q.Limit {
+		return math.MaxInt
+	}
+	return (q.Page - 1) * q.Limit
+}
+
+// Filter returns first filter of field, if any
+func (q *ListQuery) Filter(field string) (Filter, bool) {
+	for _, f := range q.Filters {
+		if f.Field == field {
+			return f, true
+		}
+	}
+	return Filter{}, false
+}
+
+// WithPage returns copy of query pointing to page n, dropping cursor
+func (q *ListQuery) WithPage(n int) *ListQuery {
+	c := *q
+	c.Page, c.Cursor = n, ""
+	return &c
+}
+
+// WithCursor returns copy of query pointing to cursor, dropping page
+func (q *ListQuery) WithCursor(cursor string) *ListQuery {
+	c := *q
+	c.Page, c.Cursor = 0, cursor
+	return &c
+}
+
+// Values renders query back into parameters ParseListQuery understands
+func (q *ListQuery) Values() url.Values {
+	values := url.Values{}
+	if q.Cursor != "" {
+		values.Set(listParamCursor, q.Cursor)
+	} else if q.Page > 0 {
+		values.Set(listParamPage, strconv.Itoa(q.Page))
+	}
+	if q.Limit > 0 {
+		values.Set(listParamLimit, strconv.Itoa(q.Limit))
+	}
+	if len(q.Sort) > 0 {
+		fields := make([]string, len(q.Sort))
+		for i, sf := range q.Sort {
+			fields[i] = sf.String()
+		}
+		values.Set(listParamSort, strings.Join(fields, ","))
+	}
+	for _, f := range q.Filters {
+		key := listParamFilter + "[" + f.Field + "]"
+		if f.Operator != FilterEq {
+			key += "[" + string(f.Operator) + "]"
+		}
+		values.Set(key, strings.Join(f.Values, ","))
+	}
+	return values
+}
+
+// Encode renders query into URL encoded query string, i.e. for pagination links
+func (q *ListQuery) Encode() string {
+	return q.Values().Encode()
+}
+
+func (sf SortField) String() string {
+	if sf.Direction == SortDesc {
+		return "-" + sf.Field
+	}
+	return sf.Field
+}
+
+func (d SortDirection) String() string {
+	if d == SortDesc {
+		return "desc"
+	}
+	return "asc"
+}
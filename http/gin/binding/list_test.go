@@ -0,0 +1,104 @@
+package binding
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testListOpts = []ListOptsFn{
+	WithSortable("name", "created_at"),
+	WithFilterable("status", FilterEq, FilterIn),
+	WithFilterable("amount"),
+	WithMaxLimit(50),
+}
+
+func Test_ParseRequestListQuery(t *testing.T) {
+	t.Parallel()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet,
+		"/?page=3&per_page=25&sort=-created_at,%2Bname&filter[status][in]=open,closed&filter[amount][gt]=10&other=x", nil)
+
+	q, err := ParseRequestListQuery(r, testListOpts...)
+	require.NoError(t, err)
+	require.Equal(t, &ListQuery{
+		Page:  3,
+		Limit: 25,
+		Sort:  []SortField{{Field: "created_at", Direction: SortDesc}, {Field: "name", Direction: SortAsc}},
+		Filters: []Filter{
+			{Field: "amount", Operator: FilterGt, Values: []string{"10"}},
+			{Field: "status", Operator: FilterIn, Values: []string{"open", "closed"}},
+		},
+	}, q)
+	require.Equal(t, 50, q.Offset())
+
+	next, err := ParseRequestListQuery(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?"+q.WithPage(q.Page+1).Encode(), nil), testListOpts...)
+	require.NoError(t, err)
+	require.Equal(t, 4, next.Page)
+	require.Equal(t, q.Sort, next.Sort)
+	require.Equal(t, q.Filters, next.Filters)
+}
+
+func Test_ParseRequestListQuery_Defaults(t *testing.T) {
+	t.Parallel()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?cursor=abc&filter[status]=open", nil)
+
+	q, err := ParseRequestListQuery(r, append(testListOpts, WithDefaultLimit(10), WithDefaultSort(SortField{Field: "name"}))...)
+	require.NoError(t, err)
+	require.Equal(t, 0, q.Page)
+	require.Equal(t, "abc", q.Cursor)
+	require.Equal(t, 10, q.Limit)
+	require.Equal(t, []SortField{{Field: "name"}}, q.Sort)
+	require.Equal(t, []Filter{{Field: "status", Operator: FilterEq, Values: []string{"open"}}}, q.Filters)
+	require.Equal(t, "cursor=abc&filter%5Bstatus%5D=open&per_page=10&sort=name", q.Encode())
+	require.Equal(t, "cursor=def&filter%5Bstatus%5D=open&per_page=10&sort=name", q.WithCursor("def").Encode())
+
+	q, err = ParseRequestListQuery(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	require.Equal(t, &ListQuery{Page: 1, Limit: 20}, q)
+
+	q, err = ParseRequestListQuery(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?sort=&sort=,", nil),
+		append(testListOpts, WithDefaultSort(SortField{Field: "name"}))...)
+	require.NoError(t, err)
+	require.Equal(t, []SortField{{Field: "name"}}, q.Sort)
+
+	q, err = ParseRequestListQuery(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?page=3", nil), WithMaxPage(2))
+	require.Error(t, err)
+	require.Nil(t, q)
+	require.Equal(t, math.MaxInt, (&ListQuery{Page: math.MaxInt, Limit: 100}).Offset())
+}
+
+func Test_ParseRequestListQuery_Errors(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		query string
+		field string
+	}{
+		{"page=0", "page"},
+		{"page=x", "page"},
+		{"per_page=51", "per_page"},
+		{"page=2147483648", "page"},
+		{"page=9223372036854775807", "page"},
+		{"page=2&cursor=abc", "cursor"},
+		{"sort=name,-secret", "sort"},
+		{"filter[secret]=1", "filter[secret]"},
+		{"filter[status][gt]=1", "filter[status][gt]"},
+		{"filter[amount][between]=1", "filter[amount][between]"},
+		{"filter[amount][gt][x]=1", "filter[amount][gt][x]"},
+		{"filter[amount]=", "filter[amount]"},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.query, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?"+p.query, nil)
+			_, err := ParseRequestListQuery(r, testListOpts...)
+			var be *BindingError
+			require.ErrorAs(t, err, &be)
+			require.Equal(t, http.StatusBadRequest, be.Code)
+			require.Equal(t, p.field, be.Field)
+		})
+	}
+}
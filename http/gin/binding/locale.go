@@ -0,0 +1,447 @@
+package binding
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	ghttp "github.com/pudottapommin/golib/http"
+)
+
+type (
+	// Msg is translatable binding error message. Binders pass it to ErrorFunc as message, NewBindingError
+	// stores its Kind and Params to BindingError and renders English text as Message.
+	Msg struct {
+		Kind   string
+		Params map[string]any
+	}
+
+	// Catalog translates error kinds into localized messages
+	Catalog interface {
+		// Translate returns message of kind in locale with `{param}` placeholders replaced by params
+		Translate(locale, kind string, params map[string]any) (string, bool)
+		// Locales lists locales catalog has messages for
+		Locales() []string
+	}
+
+	// MessageCatalog is Catalog of message templates, i.e. `value must be at least {min}`, by locale and kind
+	MessageCatalog struct {
+		mu       sync.RWMutex
+		messages map[string]map[string]string
+	}
+
+	localeContextKey struct{}
+)
+
+// DefaultLocale is used when request does not ask for any supported locale
+const DefaultLocale = "en"
+
+// Error kinds reported by binders and rules
+const (
+	KindValidationFailed       = "validation_failed"
+	KindRequired               = "required"
+	KindInvalidUUID            = "invalid_uuid"
+	KindInvalidTime            = "invalid_time"
+	KindInvalidBool            = "invalid_bool"
+	KindInvalidNumber          = "invalid_number"
+	KindInvalidDuration        = "invalid_duration"
+	KindInvalidValue           = "invalid_value"
+	KindFileRequired           = "file_required"
+	KindFileRead               = "file_read"
+	KindFileTooLarge           = "file_too_large"
+	KindFilesTooLarge          = "files_too_large"
+	KindFileType               = "file_type"
+	KindNestedNotAllowed       = "nested_not_allowed"
+	KindTooManyValues          = "too_many_values"
+	KindInvalidIndex           = "invalid_index"
+	KindIndexTooLarge          = "index_too_large"
+	KindUnsupportedContentType = "unsupported_content_type"
+	KindBodyEmpty              = "body_empty"
+	KindBodyRead               = "body_read"
+	KindBodyTooLarge           = "body_too_large"
+	KindTrailingData           = "trailing_data"
+	KindMalformedJSON          = "malformed_json"
+	KindUnexpectedEOF          = "unexpected_eof"
+	KindInvalidJSONType        = "invalid_json_type"
+	KindUnknownField           = "unknown_field"
+	KindInvalidJSON            = "invalid_json"
+	KindCursorWithPage         = "cursor_with_page"
+	KindNotSortable            = "not_sortable"
+	KindInvalidFilter          = "invalid_filter"
+	KindNotFilterable          = "not_filterable"
+	KindOperatorNotAllowed     = "operator_not_allowed"
+	KindMin                    = "min"
+	KindMax                    = "max"
+	KindLen                    = "len"
+	KindMinLen                 = "min_len"
+	KindMaxLen                 = "max_len"
+	KindFormat                 = "format"
+	KindEmail                  = "email"
+	KindURL                    = "url"
+	KindOneOf                  = "one_of"
+)
+
+var englishMessages = map[string]string{
+	KindValidationFailed:       "validation failed",
+	KindRequired:               "required field value is empty",
+	KindInvalidUUID:            "invalid uuid value",
+	KindInvalidTime:            "failed to bind field to value Time",
+	KindInvalidBool:            "failed to bind field value to bool",
+	KindInvalidNumber:          "failed to bind field value to {type}",
+	KindInvalidDuration:        "failed to bind field value to Duration",
+	KindInvalidValue:           "failed to bind field value to IBindable",
+	KindFileRequired:           "required file is missing",
+	KindFileRead:               "failed to read uploaded file",
+	KindFileTooLarge:           "file exceeds maximum size of {max} bytes",
+	KindFilesTooLarge:          "files exceed maximum total size of {max} bytes",
+	KindFileType:               "file type {type} is not allowed",
+	KindNestedNotAllowed:       "nested values are not allowed for field",
+	KindTooManyValues:          "number of values exceeds maximum of {max}",
+	KindInvalidIndex:           "invalid index",
+	KindIndexTooLarge:          "index exceeds maximum of {max}",
+	KindUnsupportedContentType: "unsupported content type",
+	KindBodyEmpty:              "request body is empty",
+	KindBodyRead:               "failed to read request body",
+	KindBodyTooLarge:           "request body too large",
+	KindTrailingData:           "unexpected data after JSON value",
+	KindMalformedJSON:          "malformed JSON at offset {offset}",
+	KindUnexpectedEOF:          "malformed JSON: unexpected end of body",
+	KindInvalidJSONType:        "invalid value of type {value}, expected {type}",
+	KindUnknownField:           "unknown field",
+	KindInvalidJSON:            "failed to decode JSON body",
+	KindCursorWithPage:         "cursor can't be combined with page",
+	KindNotSortable:            "field {field} is not sortable",
+	KindInvalidFilter:          "invalid filter",
+	KindNotFilterable:          "field {field} is not filterable",
+	KindOperatorNotAllowed:     "operator {operator} is not allowed",
+	KindMin:                    "value must be at least {min}",
+	KindMax:                    "value must be at most {max}",
+	KindLen:                    "value length must be {len}",
+	KindMinLen:                 "value length must be at least {min}",
+	KindMaxLen:                 "value length must be at most {max}",
+	KindFormat:                 "value has invalid format",
+	KindEmail:                  "invalid email address",
+	KindURL:                    "invalid URL",
+	KindOneOf:                  "value must be one of {values}",
+}
+
+var finnishMessages = map[string]string{
+	KindValidationFailed:       "validointi epäonnistui",
+	KindRequired:               "pakollinen kenttä puuttuu",
+	KindInvalidUUID:            "virheellinen UUID-arvo",
+	KindInvalidTime:            "virheellinen aika-arvo",
+	KindInvalidBool:            "virheellinen totuusarvo",
+	KindInvalidNumber:          "arvoa ei voitu muuntaa tyyppiin {type}",
+	KindInvalidDuration:        "virheellinen kesto",
+	KindInvalidValue:           "virheellinen arvo",
+	KindFileRequired:           "pakollinen tiedosto puuttuu",
+	KindFileRead:               "ladatun tiedoston lukeminen epäonnistui",
+	KindFileTooLarge:           "tiedosto ylittää enimmäiskoon {max} tavua",
+	KindFilesTooLarge:          "tiedostot ylittävät enimmäiskokonaiskoon {max} tavua",
+	KindFileType:               "tiedostotyyppi {type} ei ole sallittu",
+	KindNestedNotAllowed:       "sisäkkäiset arvot eivät ole sallittuja kentässä",
+	KindTooManyValues:          "arvojen määrä ylittää enimmäismäärän {max}",
+	KindInvalidIndex:           "virheellinen indeksi",
+	KindIndexTooLarge:          "indeksi ylittää enimmäisarvon {max}",
+	KindUnsupportedContentType: "sisältötyyppiä ei tueta",
+	KindBodyEmpty:              "pyynnön runko on tyhjä",
+	KindBodyRead:               "pyynnön rungon lukeminen epäonnistui",
+	KindBodyTooLarge:           "pyynnön runko on liian suuri",
+	KindTrailingData:           "JSON-arvon jälkeen on ylimääräistä dataa",
+	KindMalformedJSON:          "virheellinen JSON kohdassa {offset}",
+	KindUnexpectedEOF:          "virheellinen JSON: pyynnön runko päättyi odottamatta",
+	KindInvalidJSONType:        "tyypin {value} arvo on virheellinen, odotettiin tyyppiä {type}",
+	KindUnknownField:           "tuntematon kenttä",
+	KindInvalidJSON:            "JSON-rungon jäsentäminen epäonnistui",
+	KindCursorWithPage:         "kursoria ei voi käyttää yhdessä sivun kanssa",
+	KindNotSortable:            "kentän {field} mukaan ei voi järjestää",
+	KindInvalidFilter:          "virheellinen suodatin",
+	KindNotFilterable:          "kentän {field} mukaan ei voi suodattaa",
+	KindOperatorNotAllowed:     "operaattori {operator} ei ole sallittu",
+	KindMin:                    "arvon on oltava vähintään {min}",
+	KindMax:                    "arvon on oltava enintään {max}",
+	KindLen:                    "arvon pituuden on oltava {len}",
+	KindMinLen:                 "arvon pituuden on oltava vähintään {min}",
+	KindMaxLen:                 "arvon pituuden on oltava enintään {max}",
+	KindFormat:                 "arvon muoto on virheellinen",
+	KindEmail:                  "virheellinen sähköpostiosoite",
+	KindURL:                    "virheellinen URL-osoite",
+	KindOneOf:                  "arvon on oltava jokin seuraavista: {values}",
+}
+
+var swedishMessages = map[string]string{
+	KindValidationFailed:       "valideringen misslyckades",
+	KindRequired:               "obligatoriskt fält saknas",
+	KindInvalidUUID:            "ogiltigt UUID-värde",
+	KindInvalidTime:            "ogiltigt tidsvärde",
+	KindInvalidBool:            "ogiltigt booleskt värde",
+	KindInvalidNumber:          "värdet kunde inte tolkas som {type}",
+	KindInvalidDuration:        "ogiltig varaktighet",
+	KindInvalidValue:           "ogiltigt värde",
+	KindFileRequired:           "obligatorisk fil saknas",
+	KindFileRead:               "den uppladdade filen kunde inte läsas",
+	KindFileTooLarge:           "filen överskrider maxstorleken {max} byte",
+	KindFilesTooLarge:          "filerna överskrider den totala maxstorleken {max} byte",
+	KindFileType:               "filtypen {type} är inte tillåten",
+	KindNestedNotAllowed:       "nästlade värden är inte tillåtna för fältet",
+	KindTooManyValues:          "antalet värden överskrider maximum {max}",
+	KindInvalidIndex:           "ogiltigt index",
+	KindIndexTooLarge:          "index överskrider maximum {max}",
+	KindUnsupportedContentType: "innehållstypen stöds inte",
+	KindBodyEmpty:              "begärans innehåll är tomt",
+	KindBodyRead:               "begärans innehåll kunde inte läsas",
+	KindBodyTooLarge:           "begärans innehåll är för stort",
+	KindTrailingData:           "oväntade data efter JSON-värdet",
+	KindMalformedJSON:          "felaktig JSON vid position {offset}",
+	KindUnexpectedEOF:          "felaktig JSON: begärans innehåll tog slut oväntat",
+	KindInvalidJSONType:        "ogiltigt värde av typen {value}, förväntade {type}",
+	KindUnknownField:           "okänt fält",
+	KindInvalidJSON:            "JSON-innehållet kunde inte avkodas",
+	KindCursorWithPage:         "markör kan inte kombineras med sida",
+	KindNotSortable:            "fältet {field} kan inte sorteras",
+	KindInvalidFilter:          "ogiltigt filter",
+	KindNotFilterable:          "fältet {field} kan inte filtreras",
+	KindOperatorNotAllowed:     "operatorn {operator} är inte tillåten",
+	KindMin:                    "värdet måste vara minst {min}",
+	KindMax:                    "värdet måste vara högst {max}",
+	KindLen:                    "värdets längd måste vara {len}",
+	KindMinLen:                 "värdets längd måste vara minst {min}",
+	KindMaxLen:                 "värdets längd måste vara högst {max}",
+	KindFormat:                 "värdet har ogiltigt format",
+	KindEmail:                  "ogiltig e-postadress",
+	KindURL:                    "ogiltig URL",
+	KindOneOf:                  "värdet måste vara ett av {values}",
+}
+
+// DefaultCatalog is used by WriteProblem and RenderProblem to localize error messages.
+// It contains English, Finnish and Swedish messages, other locales can be added with Add or LoadFS.
+var DefaultCatalog = NewMessageCatalog()
+
+// msg creates Msg from kind and key value pairs of params
+func msg(kind string, params ...any) Msg {
+	m := Msg{Kind: kind}
+	if len(params) > 0 {
+		m.Params = make(map[string]any, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			m.Params[fmt.Sprint(params[i])] = params[i+1]
+		}
+	}
+	return m
+}
+
+// String renders message in English
+func (m Msg) String() string {
+	tmpl, ok := englishMessages[m.Kind]
+	if !ok {
+		return m.Kind
+	}
+	return formatMessage(tmpl, m.Params)
+}
+
+// MarshalText renders message in English, so that Msg marshals to JSON, XML and other text formats as
+// message string did before
+func (m Msg) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// NewMessageCatalog creates catalog containing English, Finnish and Swedish messages
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{messages: map[string]map[string]string{
+		DefaultLocale: maps.Clone(englishMessages),
+		"fi":          maps.Clone(finnishMessages),
+		"sv":          maps.Clone(swedishMessages),
+	}}
+}
+
+// Add adds messages of locale, replacing existing messages of same kind
+func (mc *MessageCatalog) Add(locale string, messages map[string]string) {
+	locale = normalizeLocale(locale)
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.messages[locale] == nil {
+		mc.messages[locale] = make(map[string]string, len(messages))
+	}
+	maps.Copy(mc.messages[locale], messages)
+}
+
+// LoadFS adds every `<locale>.json` file in dir of fsys, i.e. `fi.json` containing `{"required": "..."}`
+func (mc *MessageCatalog) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("binding: failed to load catalog %s: %w", file, err)
+		}
+		mc.Add(strings.TrimSuffix(path.Base(file), ".json"), messages)
+	}
+	return nil
+}
+
+// Translate returns message of kind in locale. Regional locale, i.e. `fi-FI`, falls back to its language
+func (mc *MessageCatalog) Translate(locale, kind string, params map[string]any) (string, bool) {
+	locale = normalizeLocale(locale)
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	tmpl, ok := mc.messages[locale][kind]
+	if !ok {
+		base, _, _ := strings.Cut(locale, "-")
+		if tmpl, ok = mc.messages[base][kind]; !ok {
+			return "", false
+		}
+	}
+	return formatMessage(tmpl, params), true
+}
+
+func (mc *MessageCatalog) Locales() []string {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return slices.Sorted(maps.Keys(mc.messages))
+}
+
+// WithLocale returns context forcing locale of error messages, overriding `Accept-Language` header
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns locale set by WithLocale
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeContextKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// RequestLocale returns locale of request error messages. Locale set by WithLocale takes precedence,
+// otherwise best match of `Accept-Language` header among catalog locales is used. Defaults to DefaultLocale.
+func RequestLocale(r *http.Request, catalog Catalog) string {
+	if locale, ok := LocaleFromContext(r.Context()); ok {
+		return locale
+	}
+	return MatchLocale(r.Header.Get(ghttp.HeaderAcceptLanguage), catalog.Locales())
+}
+
+// Locale returns locale of gin request error messages using DefaultCatalog, see RequestLocale
+func Locale(c *gin.Context) string {
+	return RequestLocale(c.Request, DefaultCatalog)
+}
+
+// MatchLocale picks supported locale best matching `Accept-Language` header value. Language ranges are
+// matched exactly first and by their language (`sv-FI` matches `sv`) after that.
+func MatchLocale(acceptLanguage string, supported []string) string {
+	type langRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []langRange
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		r := langRange{tag: normalizeLocale(tag), q: 1}
+		if r.tag == "" {
+			continue
+		}
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				r.q = v
+			}
+		}
+		if r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b langRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	for _, r := range ranges {
+		base, _, _ := strings.Cut(r.tag, "-")
+		for _, exact := range []bool{true, false} {
+			for _, locale := range supported {
+				l := normalizeLocale(locale)
+				lBase, _, _ := strings.Cut(l, "-")
+				if (exact && l == r.tag) || (!exact && lBase == base) {
+					return locale
+				}
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Localize returns copy of error with message translated to locale. Errors without kind or translation keep
+// their message.
+func (be *BindingError) Localize(locale string, catalog Catalog) *BindingError {
+	if be.Kind == "" || be.HTTPError == nil {
+		return be
+	}
+	text, ok := catalog.Translate(locale, be.Kind, be.Params)
+	if !ok {
+		return be
+	}
+	he := *be.HTTPError
+	he.Message = text
+	c := *be
+	c.HTTPError = &he
+	return &c
+}
+
+// Localize returns copy of errors with messages translated to locale
+func (ve ValidationErrors) Localize(locale string, catalog Catalog) ValidationErrors {
+	c := make(ValidationErrors, len(ve))
+	for i := range ve {
+		c[i] = ve[i].Localize(locale, catalog)
+	}
+	return c
+}
+
+// LocalizeError translates BindingError or ValidationErrors found in err. Other errors are returned as is
+func LocalizeError(err error, locale string, catalog Catalog) error {
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		return ve.Localize(locale, catalog)
+	}
+	var be *BindingError
+	if errors.As(err, &be) {
+		return be.Localize(locale, catalog)
+	}
+	return err
+}
+
+// localize translates m to locale, falling back to English
+func localize(catalog Catalog, locale string, m Msg) string {
+	if text, ok := catalog.Translate(locale, m.Kind, m.Params); ok {
+		return text
+	}
+	return m.String()
+}
+
+// formatMessage replaces `{name}` placeholders in tmpl by params
+func formatMessage(tmpl string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,147 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testCatalogFS = fstest.MapFS{
+	"locales/fi.json": {Data: []byte(`{"required": "pakollinen kenttä puuttuu", "min": "arvon on oltava vähintään {min}"}`)},
+	"locales/sv.json": {Data: []byte(`{"required": "obligatoriskt fält saknas"}`)},
+	"locales/README":  {Data: []byte(`not a catalog`)},
+}
+
+func Test_MessageCatalog(t *testing.T) {
+	t.Parallel()
+	c := NewMessageCatalog()
+	require.NoError(t, c.LoadFS(testCatalogFS, "locales"))
+	require.Equal(t, []string{"en", "fi", "sv"}, c.Locales())
+
+	text, ok := c.Translate("fi-FI", KindMin, map[string]any{"min": 18})
+	require.True(t, ok)
+	require.Equal(t, "arvon on oltava vähintään 18", text)
+
+	text, ok = c.Translate("en", KindInvalidNumber, map[string]any{"type": "int8"})
+	require.True(t, ok)
+	require.Equal(t, "failed to bind field value to int8", text)
+
+	text, ok = c.Translate("sv", KindMin, map[string]any{"min": 18})
+	require.True(t, ok)
+	require.Equal(t, "värdet måste vara minst 18", text)
+
+	_, ok = c.Translate("de", KindMin, nil)
+	require.False(t, ok)
+
+	require.Error(t, c.LoadFS(fstest.MapFS{"fi.json": {Data: []byte(`[]`)}}, "."))
+}
+
+func Test_MessageCatalog_BuiltIn(t *testing.T) {
+	t.Parallel()
+	c := NewMessageCatalog()
+	for kind := range englishMessages {
+		for _, locale := range []string{"fi", "sv"} {
+			_, ok := c.Translate(locale, kind, nil)
+			require.True(t, ok, "%s message of %s is missing", locale, kind)
+		}
+	}
+
+	err := BindRequestJSON(newJSONRequest(t, "application/json", `{"name":`), new(testOrder))
+	localized := LocalizeError(err, "fi", c).(*BindingError)
+	require.Equal(t, KindUnexpectedEOF, localized.Kind)
+	require.Equal(t, "virheellinen JSON: pyynnön runko päättyi odottamatta", localized.Message)
+}
+
+func Test_Msg_Marshal(t *testing.T) {
+	t.Parallel()
+	var message any
+	b := QueryParamsBinder(newTestContext(t, http.MethodGet, "/?age=x", nil))
+	b.ErrorFunc = func(_ string, _ []string, m any, _ error) error {
+		message = m
+		return errors.New("custom")
+	}
+	require.Error(t, b.Int("age", new(int)).BindError())
+
+	data, err := json.Marshal(map[string]any{"message": message})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"message":"failed to bind field value to int"}`, string(data))
+	require.Equal(t, "failed to bind field value to int", fmt.Sprint(message))
+}
+
+func Test_MatchLocale(t *testing.T) {
+	t.Parallel()
+	supported := []string{"en", "fi", "sv-FI"}
+	pairs := []struct {
+		accept string
+		locale string
+	}{
+		{"", "en"},
+		{"fi", "fi"},
+		{"fi-FI,fi;q=0.9,en;q=0.8", "fi"},
+		{"de, sv;q=0.7, en;q=0.5", "sv-FI"},
+		{"en;q=0.5, fi;q=0.8", "fi"},
+		{"fi;q=0, de", "en"},
+		{"SV_fi", "sv-FI"},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.accept, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, p.locale, MatchLocale(p.accept, supported))
+		})
+	}
+}
+
+func Test_BindingError_Localize(t *testing.T) {
+	t.Parallel()
+	c := NewMessageCatalog()
+	require.NoError(t, c.LoadFS(testCatalogFS, "locales"))
+
+	var age int
+	err := QueryParamsBinder(newTestContext(t, http.MethodGet, "/?age=12", nil)).FailFast(false).
+		Int("age", &age).Min(18).
+		String("name", new(string)).
+		BindError()
+	ve := err.(ValidationErrors)
+	require.Equal(t, "value must be at least 18", ve[0].Message)
+	require.Equal(t, KindMin, ve[0].Kind)
+
+	localized := LocalizeError(err, "fi", c).(ValidationErrors)
+	require.Equal(t, "arvon on oltava vähintään 18", localized[0].Message)
+	require.Equal(t, "pakollinen kenttä puuttuu", localized[1].Message)
+	require.Equal(t, "value must be at least 18", ve[0].Message, "original error is not modified")
+
+	localized = ve.Localize("sv", c)
+	require.Equal(t, "värdet måste vara minst 18", localized[0].Message)
+	require.Equal(t, "obligatoriskt fält saknas", localized[1].Message)
+}
+
+func Test_RequestLocale(t *testing.T) {
+	t.Parallel()
+	c := NewMessageCatalog()
+	c.Add("fi", map[string]string{KindRequired: "pakollinen kenttä puuttuu"})
+
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "fi-FI, en;q=0.5")
+	require.Equal(t, "fi", RequestLocale(r, c))
+	require.Equal(t, "sv", RequestLocale(r.WithContext(WithLocale(r.Context(), "sv")), c))
+}
+
+func Test_NewProblem_Localized(t *testing.T) {
+	t.Parallel()
+	DefaultCatalog.Add("x-test", map[string]string{KindRequired: "value missing", KindValidationFailed: "invalid request"})
+
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "x-test")
+	err := QueryParamsBinder(newTestContext(t, http.MethodGet, "/", nil)).FailFast(false).String("name", new(string)).BindError()
+
+	p := NewProblem(r, err)
+	require.Equal(t, "invalid request", p.Detail)
+	require.Equal(t, "value missing", p.Errors[0].Message)
+}
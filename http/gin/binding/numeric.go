@@ -0,0 +1,241 @@
+package binding
+
+import (
+	"strconv"
+	"time"
+)
+
+type (
+	signed interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64
+	}
+	unsigned interface {
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+	}
+	float interface {
+		~float32 | ~float64
+	}
+)
+
+// ShouldInt binds parameter to int variable
+func (b *ValueBinder) ShouldInt(sourceParam string, dest *int) *ValueBinder {
+	return intValue(b, sourceParam, dest, 0, false)
+}
+
+// Int requires parameter value to exist to bind to int variable. Returns error when value does not exist
+func (b *ValueBinder) Int(sourceParam string, dest *int) *ValueBinder {
+	return intValue(b, sourceParam, dest, 0, true)
+}
+
+// ShouldInt8 binds parameter to int8 variable
+func (b *ValueBinder) ShouldInt8(sourceParam string, dest *int8) *ValueBinder {
+	return intValue(b, sourceParam, dest, 8, false)
+}
+
+// Int8 requires parameter value to exist to bind to int8 variable. Returns error when value does not exist
+func (b *ValueBinder) Int8(sourceParam string, dest *int8) *ValueBinder {
+	return intValue(b, sourceParam, dest, 8, true)
+}
+
+// ShouldInt16 binds parameter to int16 variable
+func (b *ValueBinder) ShouldInt16(sourceParam string, dest *int16) *ValueBinder {
+	return intValue(b, sourceParam, dest, 16, false)
+}
+
+// Int16 requires parameter value to exist to bind to int16 variable. Returns error when value does not exist
+func (b *ValueBinder) Int16(sourceParam string, dest *int16) *ValueBinder {
+	return intValue(b, sourceParam, dest, 16, true)
+}
+
+// ShouldInt32 binds parameter to int32 variable
+func (b *ValueBinder) ShouldInt32(sourceParam string, dest *int32) *ValueBinder {
+	return intValue(b, sourceParam, dest, 32, false)
+}
+
+// Int32 requires parameter value to exist to bind to int32 variable. Returns error when value does not exist
+func (b *ValueBinder) Int32(sourceParam string, dest *int32) *ValueBinder {
+	return intValue(b, sourceParam, dest, 32, true)
+}
+
+// ShouldInt64 binds parameter to int64 variable
+func (b *ValueBinder) ShouldInt64(sourceParam string, dest *int64) *ValueBinder {
+	return intValue(b, sourceParam, dest, 64, false)
+}
+
+// Int64 requires parameter value to exist to bind to int64 variable. Returns error when value does not exist
+func (b *ValueBinder) Int64(sourceParam string, dest *int64) *ValueBinder {
+	return intValue(b, sourceParam, dest, 64, true)
+}
+
+// ShouldUint binds parameter to uint variable
+func (b *ValueBinder) ShouldUint(sourceParam string, dest *uint) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 0, false)
+}
+
+// Uint requires parameter value to exist to bind to uint variable. Returns error when value does not exist
+func (b *ValueBinder) Uint(sourceParam string, dest *uint) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 0, true)
+}
+
+// ShouldUint8 binds parameter to uint8 variable
+func (b *ValueBinder) ShouldUint8(sourceParam string, dest *uint8) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 8, false)
+}
+
+// Uint8 requires parameter value to exist to bind to uint8 variable. Returns error when value does not exist
+func (b *ValueBinder) Uint8(sourceParam string, dest *uint8) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 8, true)
+}
+
+// ShouldUint16 binds parameter to uint16 variable
+func (b *ValueBinder) ShouldUint16(sourceParam string, dest *uint16) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 16, false)
+}
+
+// Uint16 requires parameter value to exist to bind to uint16 variable. Returns error when value does not exist
+func (b *ValueBinder) Uint16(sourceParam string, dest *uint16) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 16, true)
+}
+
+// ShouldUint32 binds parameter to uint32 variable
+func (b *ValueBinder) ShouldUint32(sourceParam string, dest *uint32) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 32, false)
+}
+
+// Uint32 requires parameter value to exist to bind to uint32 variable. Returns error when value does not exist
+func (b *ValueBinder) Uint32(sourceParam string, dest *uint32) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 32, true)
+}
+
+// ShouldUint64 binds parameter to uint64 variable
+func (b *ValueBinder) ShouldUint64(sourceParam string, dest *uint64) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 64, false)
+}
+
+// Uint64 requires parameter value to exist to bind to uint64 variable. Returns error when value does not exist
+func (b *ValueBinder) Uint64(sourceParam string, dest *uint64) *ValueBinder {
+	return uintValue(b, sourceParam, dest, 64, true)
+}
+
+// ShouldFloat32 binds parameter to float32 variable
+func (b *ValueBinder) ShouldFloat32(sourceParam string, dest *float32) *ValueBinder {
+	return floatValue(b, sourceParam, dest, 32, false)
+}
+
+// Float32 requires parameter value to exist to bind to float32 variable. Returns error when value does not exist
+func (b *ValueBinder) Float32(sourceParam string, dest *float32) *ValueBinder {
+	return floatValue(b, sourceParam, dest, 32, true)
+}
+
+// ShouldFloat64 binds parameter to float64 variable
+func (b *ValueBinder) ShouldFloat64(sourceParam string, dest *float64) *ValueBinder {
+	return floatValue(b, sourceParam, dest, 64, false)
+}
+
+// Float64 requires parameter value to exist to bind to float64 variable. Returns error when value does not exist
+func (b *ValueBinder) Float64(sourceParam string, dest *float64) *ValueBinder {
+	return floatValue(b, sourceParam, dest, 64, true)
+}
+
+// ShouldDuration binds parameter to time.Duration variable. Value is parsed with time.ParseDuration
+func (b *ValueBinder) ShouldDuration(sourceParam string, dest *time.Duration) *ValueBinder {
+	return b.duration(sourceParam, dest, false)
+}
+
+// Duration requires parameter value to exist to bind to time.Duration variable. Returns error when value does not exist
+func (b *ValueBinder) Duration(sourceParam string, dest *time.Duration) *ValueBinder {
+	return b.duration(sourceParam, dest, true)
+}
+
+func (b *ValueBinder) duration(sourceParam string, dest *time.Duration, valueMustExist bool) *ValueBinder {
+	if b.bound(sourceParam, dest) {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
+		}
+		return b
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidDuration), err))
+		return b
+	}
+	*dest = d
+	return b
+}
+
+// intValue binds parameter to any signed integer. bitSize 0 means platform int size
+func intValue[T signed](b *ValueBinder, sourceParam string, dest *T, bitSize int, valueMustExist bool) *ValueBinder {
+	if b.bound(sourceParam, dest) {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
+		}
+		return b
+	}
+	n, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidNumber, "type", numberTypeName("int", bitSize)), err))
+		return b
+	}
+	*dest = T(n)
+	return b
+}
+
+// uintValue binds parameter to any unsigned integer. bitSize 0 means platform uint size
+func uintValue[T unsigned](b *ValueBinder, sourceParam string, dest *T, bitSize int, valueMustExist bool) *ValueBinder {
+	if b.bound(sourceParam, dest) {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
+		}
+		return b
+	}
+	n, err := strconv.ParseUint(value, 10, bitSize)
+	if err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidNumber, "type", numberTypeName("uint", bitSize)), err))
+		return b
+	}
+	*dest = T(n)
+	return b
+}
+
+func floatValue[T float](b *ValueBinder, sourceParam string, dest *T, bitSize int, valueMustExist bool) *ValueBinder {
+	if b.bound(sourceParam, dest) {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindRequired), nil))
+		}
+		return b
+	}
+	n, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, msg(KindInvalidNumber, "type", numberTypeName("float", bitSize)), err))
+		return b
+	}
+	*dest = T(n)
+	return b
+}
+
+func numberTypeName(name string, bitSize int) string {
+	if bitSize == 0 {
+		return name
+	}
+	return name + strconv.Itoa(bitSize)
+}
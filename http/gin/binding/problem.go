@@ -0,0 +1,198 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ghttp "github.com/pudottapommin/golib/http"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	mimeApplicationJSON        = "application/json"
+	mimeTextHTML               = "text/html"
+	mimeTextPlain              = "text/plain"
+)
+
+// problemOffers are response formats in order of preference when client accepts several of them equally
+var problemOffers = []string{MIMEApplicationProblemJSON, mimeApplicationJSON, mimeTextHTML, mimeTextPlain}
+
+// Problem is RFC 9457 problem details object
+type Problem struct {
+	// Type is URI identifying problem type, `about:blank` when problem has no additional semantics
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors is extension member listing fields that failed to bind
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+var problemHTML = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+{{- if .Detail}}
+<p>{{.Detail}}</p>
+{{- end}}
+{{- if .Errors}}
+<ul>
+{{- range .Errors}}
+<li><strong>{{.Field}}</strong>: {{.Message}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// NewProblem converts err into problem details. HTTPError, BindingError and ValidationErrors are found
+// also when wrapped. Any other error is reported as 500 without detail, so internal errors are not leaked.
+// Detail of 5xx errors is likewise omitted.
+//
+// Binding error messages are translated with DefaultCatalog to locale of request, see RequestLocale.
+func NewProblem(r *http.Request, err error) *Problem {
+	p := &Problem{Type: "about:blank", Status: http.StatusInternalServerError}
+	locale := DefaultLocale
+	if r != nil {
+		p.Instance = r.URL.Path
+		locale = RequestLocale(r, DefaultCatalog)
+	}
+
+	var (
+		ve ValidationErrors
+		be *BindingError
+		he *HTTPError
+	)
+	switch {
+	case errors.As(err, &ve):
+		p.Status, p.Errors = http.StatusBadRequest, ve.Localize(locale, DefaultCatalog)
+		p.Detail = localize(DefaultCatalog, locale, msg(KindValidationFailed))
+	case errors.As(err, &be):
+		be = be.Localize(locale, DefaultCatalog)
+		p.Status, p.Detail, p.Errors = http.StatusBadRequest, be.text(), ValidationErrors{be}
+		if be.HTTPError != nil {
+			p.Status = be.Code
+		}
+	case errors.As(err, &he):
+		p.Status = he.Code
+		if he.Message != nil {
+			p.Detail = fmt.Sprint(he.Message)
+		}
+	}
+
+	// code of custom ErrorFunc errors may be unset, net/http panics on writing invalid status
+	if p.Status < 100 || p.Status > 599 {
+		p.Status = http.StatusInternalServerError
+	}
+
+	if p.Status >= http.StatusInternalServerError {
+		p.Detail = ""
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// WriteProblem writes err as problem details to response, see NewProblem and Problem.Write
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	NewProblem(r, err).Write(w, r)
+}
+
+// RenderProblem writes err as problem details to gin response and aborts handler chain
+func RenderProblem(c *gin.Context, err error) {
+	WriteProblem(c.Writer, c.Request, err)
+	c.Abort()
+}
+
+// Write writes problem as `application/problem+json`. Clients that prefer `text/html` or `text/plain`
+// in `Accept` header get problem rendered in that format instead.
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	var accept string
+	if r != nil {
+		accept = r.Header.Get(ghttp.HeaderAccept)
+	}
+	contentType := negotiateContentType(accept, problemOffers)
+
+	w.Header().Add(ghttp.HeaderVary, ghttp.HeaderAccept)
+	w.Header().Set(ghttp.HeaderXContentTypeOptions, "nosniff")
+	switch contentType {
+	case mimeTextHTML:
+		w.Header().Set(ghttp.HeaderContentType, "text/html; charset=utf-8")
+		w.WriteHeader(p.Status)
+		_ = problemHTML.Execute(w, p)
+	case mimeTextPlain:
+		w.Header().Set(ghttp.HeaderContentType, "text/plain; charset=utf-8")
+		w.WriteHeader(p.Status)
+		_, _ = w.Write([]byte(p.String()))
+	default:
+		w.Header().Set(ghttp.HeaderContentType, contentType)
+		w.WriteHeader(p.Status)
+		_ = json.NewEncoder(w).Encode(p)
+	}
+}
+
+// String renders problem as plain text
+func (p *Problem) String() string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(p.Status) + " " + p.Title + "\n")
+	if p.Detail != "" {
+		sb.WriteString(p.Detail + "\n")
+	}
+	for _, be := range p.Errors {
+		sb.WriteString(be.Field + ": " + be.text() + "\n")
+	}
+	return sb.String()
+}
+
+// negotiateContentType picks offer with highest quality in accept header. Ties are resolved by order of offers
+// and first offer is returned when nothing matches.
+func negotiateContentType(accept string, offers []string) string {
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns quality of most specific accept range matching mediaType
+func acceptQuality(accept, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for part := range strings.SplitSeq(accept, ",") {
+		rng, params, _ := strings.Cut(part, ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+
+		s := -1
+		switch rng {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+	}
+	return q
+}
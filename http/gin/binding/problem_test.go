@@ -0,0 +1,102 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewProblem(t *testing.T) {
+	t.Parallel()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/orders?x=1", nil)
+	ve := ValidationErrors{}
+	ve.Add(NewBindingError("id", []string{"x"}, "invalid id", nil))
+	ve.Add(NewBindingError("qty", nil, "required field value is empty", nil))
+
+	pairs := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+		fields int
+	}{
+		{"validation errors", fmt.Errorf("create order: %w", ve), http.StatusBadRequest, "validation failed", 2},
+		{"binding error", withCode(NewBindingError("file", nil, "too large", nil), http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge, "too large", 1},
+		{"http error", fmt.Errorf("wrapped: %w", &HTTPError{Code: http.StatusNotFound, Message: "order not found"}), http.StatusNotFound, "order not found", 0},
+		{"http error 5xx", &HTTPError{Code: http.StatusBadGateway, Message: "upstream said no", Internal: errors.New("secret")}, http.StatusBadGateway, "", 0},
+		{"other error", errors.New("secret"), http.StatusInternalServerError, "", 0},
+		{"binding error without http error", &BindingError{Field: "id"}, http.StatusBadRequest, "", 1},
+		{"http error without code", &HTTPError{Message: "custom"}, http.StatusInternalServerError, "", 0},
+		{"http error with invalid code", &HTTPError{Code: 1000, Message: "custom"}, http.StatusInternalServerError, "", 0},
+		{"binding error without code", &BindingError{Field: "id", HTTPError: &HTTPError{Message: "custom"}}, http.StatusInternalServerError, "", 1},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			problem := NewProblem(r, p.err)
+			require.Equal(t, "about:blank", problem.Type)
+			require.Equal(t, p.status, problem.Status)
+			require.Equal(t, http.StatusText(p.status), problem.Title)
+			require.Equal(t, p.detail, problem.Detail)
+			require.Equal(t, "/orders", problem.Instance)
+			require.Len(t, problem.Errors, p.fields)
+			w := httptest.NewRecorder()
+			problem.Write(w, r)
+			require.Equal(t, p.status, w.Code)
+		})
+	}
+}
+
+func Test_WriteProblem(t *testing.T) {
+	t.Parallel()
+	err := NewBindingError("name", []string{"<b>"}, "value has <invalid> format", nil)
+	pairs := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", MIMEApplicationProblemJSON},
+		{"*/*", MIMEApplicationProblemJSON},
+		{"application/json", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html; charset=utf-8"},
+		{"text/plain, application/json;q=0.5", "text/plain; charset=utf-8"},
+		{"text/*;q=0.5, text/plain;q=0, application/problem+json;q=0.1", "text/html; charset=utf-8"},
+		{"image/png", MIMEApplicationProblemJSON},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.accept, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/users", nil)
+			r.Header.Set("Accept", p.accept)
+			w := httptest.NewRecorder()
+			WriteProblem(w, r, err)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, p.contentType, w.Header().Get("Content-Type"))
+			require.Equal(t, "Accept", w.Header().Get("Vary"))
+			switch p.contentType {
+			case "text/html; charset=utf-8":
+				require.Contains(t, w.Body.String(), "<li><strong>name</strong>: value has &lt;invalid&gt; format</li>")
+			case "text/plain; charset=utf-8":
+				require.Equal(t, "400 Bad Request\nvalue has <invalid> format\nname: value has <invalid> format\n", w.Body.String())
+			default:
+				var body map[string]any
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				require.Equal(t, map[string]any{
+					"type":     "about:blank",
+					"title":    "Bad Request",
+					"status":   float64(http.StatusBadRequest),
+					"detail":   "value has <invalid> format",
+					"instance": "/users",
+					"errors":   []any{map[string]any{"field": "name", "values": []any{"<b>"}, "message": "value has <invalid> format"}},
+				}, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package binding
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RequestFormFieldBinder creates form field value binder for net/http request.
+// Form parsing follows same rules as FormFieldBinder
+func RequestFormFieldBinder(r *http.Request) *ValueBinder {
+	return &ValueBinder{
+		failFast:  true,
+		ValueFunc: r.FormValue,
+		ValuesFunc: func(sourceParam string) []string {
+			if r.Form == nil {
+				// this is same as `Request().FormValue()` does internally
+				_ = r.ParseMultipartForm(32 << 20)
+			}
+			values, ok := r.Form[sourceParam]
+			if !ok {
+				return nil
+			}
+			return values
+		},
+		FilesFunc: multipartFiles(r),
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// RequestQueryParamsBinder creates query parameter value binder for net/http request
+func RequestQueryParamsBinder(r *http.Request) *ValueBinder {
+	var query url.Values
+	values := func() url.Values {
+		if query == nil {
+			query = r.URL.Query()
+		}
+		return query
+	}
+	return &ValueBinder{
+		failFast: true,
+		ValueFunc: func(sourceParam string) string {
+			return values().Get(sourceParam)
+		},
+		ValuesFunc: func(sourceParam string) []string {
+			v, ok := values()[sourceParam]
+			if !ok {
+				return nil
+			}
+			return v
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// ChiPathParamsBinder creates path parameter value binder reading params with chi.URLParam
+func ChiPathParamsBinder(r *http.Request) *ValueBinder {
+	return &ValueBinder{
+		failFast: true,
+		ValueFunc: func(sourceParam string) string {
+			return chi.URLParam(r, sourceParam)
+		},
+		ValuesFunc: func(sourceParam string) []string {
+			value := chi.URLParam(r, sourceParam)
+			if value == "" {
+				return nil
+			}
+			return []string{value}
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// RequestHeaderBinder creates request header value binder for net/http request
+func RequestHeaderBinder(r *http.Request) *ValueBinder {
+	return &ValueBinder{
+		failFast:  true,
+		ValueFunc: r.Header.Get,
+		ValuesFunc: func(sourceParam string) []string {
+			values := r.Header.Values(sourceParam)
+			if len(values) == 0 {
+				return nil
+			}
+			return values
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// RequestCookieBinder creates cookie value binder for net/http request
+func RequestCookieBinder(r *http.Request) *ValueBinder {
+	return &ValueBinder{
+		failFast:   true,
+		ValueFunc:  cookieValue(r),
+		ValuesFunc: cookieValues(r),
+		ErrorFunc:  NewBindingError,
+	}
+}
+
+// BindRequest binds net/http request values into struct pointed to by dst. Path params are read with chi.URLParam.
+// See Bind for supported struct tags.
+func BindRequest(r *http.Request, dst any, opts ...BindOptsFn) error {
+	return bind(dst, map[Source]*ValueBinder{
+		SourcePath:   ChiPathParamsBinder(r),
+		SourceQuery:  RequestQueryParamsBinder(r),
+		SourceForm:   RequestFormFieldBinder(r),
+		SourceHeader: RequestHeaderBinder(r),
+		SourceCookie: RequestCookieBinder(r),
+	}, opts)
+}
+
+func multipartFiles(r *http.Request) func(string) []*multipart.FileHeader {
+	return func(sourceParam string) []*multipart.FileHeader {
+		if r.MultipartForm == nil {
+			if err := r.ParseMultipartForm(32 << 20); err != nil {
+				return nil
+			}
+		}
+		return r.MultipartForm.File[sourceParam]
+	}
+}
+
+func cookieValue(r *http.Request) func(string) string {
+	return func(sourceParam string) string {
+		cookie, err := r.Cookie(sourceParam)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	}
+}
+
+func cookieValues(r *http.Request) func(string) []string {
+	return func(sourceParam string) []string {
+		cookies := r.CookiesNamed(sourceParam)
+		if len(cookies) == 0 {
+			return nil
+		}
+		values := make([]string, len(cookies))
+		for i := range cookies {
+			values[i] = cookies[i].Value
+		}
+		return values
+	}
+}
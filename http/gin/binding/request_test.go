@@ -0,0 +1,83 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Request_Binders(t *testing.T) {
+	t.Parallel()
+	id := uuid.Must(uuid.NewV4())
+
+	r := chi.NewRouter()
+	r.Post("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var (
+			pathID  uuid.UUID
+			page    int
+			name    string
+			token   string
+			session string
+		)
+		err := ChiPathParamsBinder(r).UUID("id", &pathID).BindError()
+		require.NoError(t, err)
+		require.NoError(t, RequestQueryParamsBinder(r).Int("page", &page).BindError())
+		require.NoError(t, RequestFormFieldBinder(r).String("name", &name).BindError())
+		require.NoError(t, RequestHeaderBinder(r).String("X-Token", &token).BindError())
+		require.NoError(t, RequestCookieBinder(r).String("session", &session).BindError())
+
+		require.Equal(t, id, pathID)
+		require.Equal(t, 3, page)
+		require.Equal(t, "form", name)
+		require.Equal(t, "secret", token)
+		require.Equal(t, "cookie", session)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/items/"+id.String()+"?page=3", strings.NewReader("name=form"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Token", "secret")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "cookie"})
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func Test_BindRequest(t *testing.T) {
+	t.Parallel()
+	type request struct {
+		ID      uuid.UUID `path:"id,required"`
+		Page    int       `query:"page"`
+		Session string    `cookie:"session,required"`
+	}
+	id := uuid.Must(uuid.NewV4())
+
+	r := chi.NewRouter()
+	r.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var dst request
+		if err := BindRequest(r, &dst); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		require.Equal(t, request{ID: id, Page: 2, Session: "abc"}, dst)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/items/"+id.String()+"?page=2", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/items/"+id.String(), nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "field=session")
+}
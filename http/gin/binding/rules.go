@@ -0,0 +1,292 @@
+package binding
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pudottapommin/golib/pkg/set"
+)
+
+type (
+	// boundValue is the value rules are checked against
+	boundValue struct {
+		key  string
+		dest any
+		// errors is number of binder errors before value was bound
+		errors int
+		// rule is failure of immediately preceding rule, nil when it passed or was skipped
+		rule *ruleFailure
+	}
+	ruleFailure struct {
+		index  int
+		values []string
+	}
+)
+
+// Required fails when value of previously bound parameter is empty. Useful after Should* binders
+func (b *ValueBinder) Required() *ValueBinder {
+	last, ok := b.ruleTarget()
+	if !ok {
+		return b
+	}
+	if !b.present(last.key) {
+		b.ruleError(last, msg(KindRequired))
+	}
+	return b
+}
+
+// Min fails when previously bound number is less than n. Slice values are checked element by element.
+// Previously bound value that is not number or slice of numbers, i.e. file, is reported as error with Code 500
+// and ErrUnsupportedType as internal error, because the chain itself is wrong
+func (b *ValueBinder) Min(n float64) *ValueBinder {
+	return b.numberRule("Min", func(f float64) bool {
+		return f >= n
+	}, msg(KindMin, "min", n))
+}
+
+// Max fails when previously bound number is greater than n. Slice values are checked element by element.
+// Previously bound value that is not number or slice of numbers is reported like in Min
+func (b *ValueBinder) Max(n float64) *ValueBinder {
+	return b.numberRule("Max", func(f float64) bool {
+		return f <= n
+	}, msg(KindMax, "max", n))
+}
+
+// Len fails when previously bound string does not have exactly n characters or slice does not have n elements
+func (b *ValueBinder) Len(n int) *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		return lengthOf(v, raw) == n
+	}, msg(KindLen, "len", n))
+}
+
+// MinLen fails when previously bound string has fewer than n characters or slice has fewer than n elements
+func (b *ValueBinder) MinLen(n int) *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		return lengthOf(v, raw) >= n
+	}, msg(KindMinLen, "min", n))
+}
+
+// MaxLen fails when previously bound string has more than n characters or slice has more than n elements
+func (b *ValueBinder) MaxLen(n int) *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		return lengthOf(v, raw) <= n
+	}, msg(KindMaxLen, "max", n))
+}
+
+// Matches fails when previously bound value does not match re. Slice values are checked element by element
+func (b *ValueBinder) Matches(re *regexp.Regexp) *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		for _, s := range stringsOf(v, raw) {
+			if !re.MatchString(s) {
+				return false
+			}
+		}
+		return true
+	}, msg(KindFormat))
+}
+
+// Email fails when previously bound value is not plain email address (without display name)
+func (b *ValueBinder) Email() *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		for _, s := range stringsOf(v, raw) {
+			addr, err := mail.ParseAddress(s)
+			if err != nil || addr.Address != s {
+				return false
+			}
+		}
+		return true
+	}, msg(KindEmail))
+}
+
+// URL fails when previously bound value is not absolute URL
+func (b *ValueBinder) URL() *ValueBinder {
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		for _, s := range stringsOf(v, raw) {
+			u, err := url.ParseRequestURI(s)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return false
+			}
+		}
+		return true
+	}, msg(KindURL))
+}
+
+// OneOf fails when previously bound value is not one of values. Values are compared by their string form,
+// so `OneOf(1, 2)` works for any integer type and `OneOf("a", "b")` for named string types.
+func (b *ValueBinder) OneOf(values ...any) *ValueBinder {
+	allowed := make(set.Set[string], len(values))
+	names := make([]string, len(values))
+	for i := range values {
+		names[i] = fmt.Sprint(values[i])
+		allowed.Add(names[i])
+	}
+	return b.rule(func(v reflect.Value, raw []string) bool {
+		return allowed.IsSubset(stringsOf(v, raw)...)
+	}, msg(KindOneOf, "values", strings.Join(names, ", ")))
+}
+
+// Message replaces message of error reported by immediately preceding rule. Does nothing when the rule passed.
+//
+//	b.Int("age", &age).Min(18).Message("you must be adult")
+func (b *ValueBinder) Message(message any) *ValueBinder {
+	if b.last == nil || b.last.rule == nil || b.last.rule.index >= len(b.errors) {
+		return b
+	}
+	b.errors[b.last.rule.index] = b.ErrorFunc(b.last.key, b.last.rule.values, message, nil)
+	return b
+}
+
+// rule checks previously bound value with check. Absent values and values that failed to bind are skipped
+func (b *ValueBinder) rule(check func(v reflect.Value, raw []string) bool, message any) *ValueBinder {
+	last, ok := b.ruleTarget()
+	if !ok || !b.present(last.key) {
+		return b
+	}
+
+	var v reflect.Value
+	if rv := reflect.ValueOf(last.dest); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		v = rv.Elem()
+	}
+	if !check(v, b.rawValues(last.key)) {
+		b.ruleError(last, message)
+	}
+	return b
+}
+
+// numberRule checks every number of previously bound number or slice of numbers with check
+func (b *ValueBinder) numberRule(name string, check func(f float64) bool, message any) *ValueBinder {
+	if last := b.last; last != nil && !isNumberDest(last.dest) {
+		last.rule = nil
+		if b.failFast && b.errors != nil {
+			return b
+		}
+		err := fmt.Errorf("%w %T of %s, %s rule requires number or slice of numbers", ErrUnsupportedType, last.dest, last.key, name)
+		b.setError(withCode(b.ErrorFunc(last.key, b.rawValues(last.key), msg(KindValidationFailed), err), http.StatusInternalServerError))
+		return b
+	}
+	return b.rule(func(v reflect.Value, _ []string) bool {
+		if v.Kind() == reflect.Slice {
+			for i := range v.Len() {
+				if f, _ := numberOf(v.Index(i)); !check(f) {
+					return false
+				}
+			}
+			return true
+		}
+		f, _ := numberOf(v)
+		return check(f)
+	}, message)
+}
+
+// ruleTarget returns value rules apply to, unless there is none or it already failed
+func (b *ValueBinder) ruleTarget() (*boundValue, bool) {
+	last := b.last
+	if last == nil {
+		return nil, false
+	}
+	last.rule = nil
+	if (b.failFast && b.errors != nil) || len(b.errors) > last.errors {
+		return nil, false
+	}
+	return last, true
+}
+
+func (b *ValueBinder) ruleError(last *boundValue, message any) {
+	values := b.rawValues(last.key)
+	if len(values) == 0 {
+		values = []string{""}
+	}
+	b.setError(b.ErrorFunc(last.key, values, message, nil))
+	last.rule = &ruleFailure{index: len(b.errors) - 1, values: values}
+}
+
+func (b *ValueBinder) present(sourceParam string) bool {
+	return len(b.rawValues(sourceParam)) > 0
+}
+
+// rawValues returns non-empty request values of parameter
+func (b *ValueBinder) rawValues(sourceParam string) []string {
+	if b.ValuesFunc != nil {
+		values := make([]string, 0, 1)
+		for _, value := range b.ValuesFunc(sourceParam) {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			return values
+		}
+	}
+	if value := b.ValueFunc(sourceParam); value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// isNumberDest reports whether dest is pointer to number or to slice of numbers
+func isNumberDest(dest any) bool {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return false
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	_, ok := numberOf(reflect.Zero(t))
+	return ok
+}
+
+func lengthOf(v reflect.Value, raw []string) int {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String())
+	case reflect.Slice:
+		return v.Len()
+	}
+	if len(raw) == 0 {
+		return 0
+	}
+	return utf8.RuneCountInString(raw[0])
+}
+
+// stringsOf returns bound value as strings, falling back to raw request values for types without natural string form
+func stringsOf(v reflect.Value, raw []string) []string {
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Slice:
+		values := make([]string, v.Len())
+		for i := range v.Len() {
+			values[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return values
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return []string{fmt.Sprint(v.Interface())}
+	}
+	if len(raw) == 0 {
+		return raw
+	}
+	return raw[:1]
+}
@@ -0,0 +1,113 @@
+package binding
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValueBinder_Rules(t *testing.T) {
+	t.Parallel()
+	type status string
+	pairs := []struct {
+		name  string
+		query string
+		bind  func(*ValueBinder) *ValueBinder
+		fails bool
+	}{
+		{"min ok", "/?v=18", func(b *ValueBinder) *ValueBinder { return b.Int("v", new(int)).Min(18).Max(130) }, false},
+		{"min", "/?v=17", func(b *ValueBinder) *ValueBinder { return b.Int("v", new(int)).Min(18) }, true},
+		{"max", "/?v=2.5", func(b *ValueBinder) *ValueBinder { return b.Float64("v", new(float64)).Max(2) }, true},
+		{"min slice ok", "/?v=1,2", func(b *ValueBinder) *ValueBinder { return b.Ints("v", new([]int)).Min(1).Max(2) }, false},
+		{"min slice", "/?v=1,0", func(b *ValueBinder) *ValueBinder { return b.Ints("v", new([]int)).Min(1) }, true},
+		{"max slice", "/?v=1,5", func(b *ValueBinder) *ValueBinder { return b.Ints("v", new([]int)).Max(3) }, true},
+		{"len ok", "/?v=abcdef", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).Len(6) }, false},
+		{"len runes", "/?v=äöå", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).Len(3) }, false},
+		{"len", "/?v=abc", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).Len(6) }, true},
+		{"min len slice", "/?v=1,2", func(b *ValueBinder) *ValueBinder { return b.Ints("v", new([]int)).MinLen(3) }, true},
+		{"max len", "/?v=abcd", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).MaxLen(3) }, true},
+		{"matches ok", "/?v=AB12", func(b *ValueBinder) *ValueBinder {
+			return b.String("v", new(string)).Matches(regexp.MustCompile(`^[A-Z]{2}\d{2}$`))
+		}, false},
+		{"matches", "/?v=ab12", func(b *ValueBinder) *ValueBinder {
+			return b.String("v", new(string)).Matches(regexp.MustCompile(`^[A-Z]{2}\d{2}$`))
+		}, true},
+		{"email ok", "/?v=a@example.com", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).Email() }, false},
+		{"email", "/?v=Name%20%3Ca@example.com%3E", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).Email() }, true},
+		{"url ok", "/?v=https://example.com/a", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).URL() }, false},
+		{"url", "/?v=/relative", func(b *ValueBinder) *ValueBinder { return b.String("v", new(string)).URL() }, true},
+		{"one of ok", "/?v=2", func(b *ValueBinder) *ValueBinder { return b.Int8("v", new(int8)).OneOf(1, 2) }, false},
+		{"one of custom", "/?v=open", func(b *ValueBinder) *ValueBinder {
+			return b.CustomFunc("v", func(string) error { return nil }).OneOf(status("open"), "closed")
+		}, false},
+		{"one of slice", "/?v=a,c", func(b *ValueBinder) *ValueBinder { return b.Strings("v", new([]string)).OneOf("a", "b") }, true},
+		{"required", "/", func(b *ValueBinder) *ValueBinder { return b.ShouldString("v", new(string)).Required() }, true},
+		{"absent optional", "/", func(b *ValueBinder) *ValueBinder { return b.ShouldInt("v", new(int)).Min(10).Email() }, false},
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			c := newTestContext(t, http.MethodGet, p.query, nil)
+			err := p.bind(QueryParamsBinder(c)).BindError()
+			if !p.fails {
+				require.NoError(t, err)
+				return
+			}
+			var be *BindingError
+			require.ErrorAs(t, err, &be)
+			require.Equal(t, "v", be.Field)
+		})
+	}
+}
+
+func Test_ValueBinder_NumberRuleMisuse(t *testing.T) {
+	t.Parallel()
+	c := newTestContext(t, http.MethodGet, "/?x=abcd", nil)
+	pairs := []struct {
+		name string
+		bind func(b *ValueBinder) *ValueBinder
+	}{
+		{"string", func(b *ValueBinder) *ValueBinder { return b.String("x", new(string)).Max(3) }},
+		{"absent strings", func(b *ValueBinder) *ValueBinder { return b.ShouldStrings("y", new([]string)).Min(1) }},
+		{"absent uuid", func(b *ValueBinder) *ValueBinder { return b.ShouldUUID("y", new(uuid.UUID)).Min(1) }},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			var err error
+			require.NotPanics(t, func() { err = p.bind(QueryParamsBinder(c)).BindError() })
+			var be *BindingError
+			require.ErrorAs(t, err, &be)
+			require.ErrorIs(t, be.Internal, ErrUnsupportedType)
+			require.Equal(t, http.StatusInternalServerError, be.Code)
+		})
+	}
+}
+
+func Test_ValueBinder_RuleMessages(t *testing.T) {
+	t.Parallel()
+	c := newTestContext(t, http.MethodGet, "/?age=12&code=abc&name=x", nil)
+
+	var (
+		age  int
+		code string
+		name string
+	)
+	err := QueryParamsBinder(c).FailFast(false).
+		Int("age", &age).Min(18).Message("you must be adult").Max(130).
+		String("code", &code).Len(6).Matches(regexp.MustCompile(`^\d+$`)).Message("not used").
+		String("name", &name).MinLen(1).Message("not used").
+		BindError()
+
+	var ve ValidationErrors
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve, 2)
+	require.Equal(t, "you must be adult", ve[0].Message)
+	require.Equal(t, []string{"12"}, ve[0].Values)
+	require.Equal(t, "code", ve[1].Field)
+	require.Equal(t, "value length must be 6", ve[1].Message)
+}
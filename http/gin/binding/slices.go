@@ -0,0 +1,108 @@
+package binding
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ShouldStrings binds all parameter values to string slice. i.e. `?tags=a&tags=b` or `?tags=a,b`
+func (b *ValueBinder) ShouldStrings(sourceParam string, dest *[]string) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, false, true, Msg{}, func(s string) (string, error) { return s, nil })
+}
+
+// Strings requires parameter values to exist to bind to string slice. Returns error when no value exists
+func (b *ValueBinder) Strings(sourceParam string, dest *[]string) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, true, true, Msg{}, func(s string) (string, error) { return s, nil })
+}
+
+// ShouldInts binds all parameter values to int slice. i.e. `?ids=1&ids=2` or `?ids=1,2`
+func (b *ValueBinder) ShouldInts(sourceParam string, dest *[]int) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, false, true, msg(KindInvalidNumber, "type", "int"), strconv.Atoi)
+}
+
+// Ints requires parameter values to exist to bind to int slice. Returns error when no value exists
+func (b *ValueBinder) Ints(sourceParam string, dest *[]int) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, true, true, msg(KindInvalidNumber, "type", "int"), strconv.Atoi)
+}
+
+// ShouldUUIDs binds all parameter values to uuid slice
+func (b *ValueBinder) ShouldUUIDs(sourceParam string, dest *[]uuid.UUID) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, false, true, msg(KindInvalidUUID), uuid.FromString)
+}
+
+// UUIDs requires parameter values to exist to bind to uuid slice. Returns error when no value exists
+func (b *ValueBinder) UUIDs(sourceParam string, dest *[]uuid.UUID) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, true, true, msg(KindInvalidUUID), uuid.FromString)
+}
+
+// ShouldTimes binds all parameter values to time slice using given layout. Values are split on commas only when
+// layout has none, so values of layouts like time.RFC1123 must be passed as separate parameters
+func (b *ValueBinder) ShouldTimes(sourceParam string, dest *[]time.Time, layout string) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, false, isCommaSafe(layout), msg(KindInvalidTime, "layout", layout), timeParser(layout))
+}
+
+// Times requires parameter values to exist to bind to time slice. Returns error when no value exists.
+// Values are split like in ShouldTimes
+func (b *ValueBinder) Times(sourceParam string, dest *[]time.Time, layout string) *ValueBinder {
+	return sliceValue(b, sourceParam, dest, true, isCommaSafe(layout), msg(KindInvalidTime, "layout", layout), timeParser(layout))
+}
+
+// sliceValue binds parameter values parsed by parse, comma separated values are split when split is set
+func sliceValue[T any](b *ValueBinder, sourceParam string, dest *[]T, valueMustExist, split bool, message Msg, parse func(string) (T, error)) *ValueBinder {
+	if b.bound(sourceParam, dest) {
+		return b
+	}
+
+	values := b.ValuesFunc(sourceParam)
+	if split {
+		values = splitValues(values)
+	} else {
+		values = slices.DeleteFunc(slices.Clone(values), func(s string) bool { return s == "" })
+	}
+	if len(values) == 0 {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{}, msg(KindRequired), nil))
+		}
+		return b
+	}
+
+	result := make([]T, 0, len(values))
+	for _, value := range values {
+		v, err := parse(value)
+		if err != nil {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, message, err))
+			return b
+		}
+		result = append(result, v)
+	}
+	*dest = result
+	return b
+}
+
+// splitValues flattens comma separated values and drops empty ones
+func splitValues(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		for part := range strings.SplitSeq(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// isCommaSafe reports whether values of time layout never contain comma, so they can be comma separated
+func isCommaSafe(layout string) bool {
+	return !strings.Contains(layout, ",")
+}
+
+func timeParser(layout string) func(string) (time.Time, error) {
+	return func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	}
+}
@@ -5,30 +5,120 @@ import (
 	"errors"
 	"net/http"
 
+	ghttp "github.com/pudottapommin/golib/http"
 	gAuth "github.com/pudottapommin/golib/pkg/auth"
 )
 
+// Handler authenticates with DefaultScheme
 func (m *mw[T]) Handler(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cv, err := gAuth.GetCookie(r, m.AuthConfig)
-		if errors.Is(err, gAuth.ErrorAuthCookieMissing) {
-			if m.NotAuthenticatedHandler != nil {
-				m.NotAuthenticatedHandler(w, r)
+	return m.HandlerFor(m.DefaultScheme)(next)
+}
+
+// HandlerFor authenticates with first of named schemes request carries credentials of, i.e. to allow Bearer tokens
+// on API routes only. Request failing authentication is challenged by every scheme.
+func (m *mw[T]) HandlerFor(names ...string) func(http.Handler) http.Handler {
+	if len(names) == 0 {
+		names = []string{m.DefaultScheme}
+	}
+	schemes := make([]Scheme, len(names))
+	for i, name := range names {
+		schemes[i] = m.scheme(name)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				cv     *gAuth.CookieValue
+				err    = ErrNoCredentials
+				scheme Scheme
+			)
+			for _, s := range schemes {
+				c, e := s.Authenticate(w, r)
+				if errors.Is(e, ErrNoCredentials) {
+					continue
+				}
+				// rejected credentials are reported only when no other scheme authenticates request
+				if isRejected(e) {
+					if scheme == nil {
+						scheme, err = s, e
+					}
+					continue
+				}
+				cv, err, scheme = c, e, s
+				break
+			}
+			if gAuth.IsSignOutError(err) {
+				if m.SignOutHandler != nil {
+					m.SignOutHandler(w, r)
+					return
+				}
+				_ = scheme.SignOut(w, r)
+				m.challenge(w, schemes, scheme, err)
+				m.notAuthenticated(w, r)
+				return
+			} else if errors.Is(err, ErrNoCredentials) || isRejected(err) {
+				m.challenge(w, schemes, scheme, err)
+				m.notAuthenticated(w, r)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		} else if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		var identity T
-		if identity, err = m.Factory(w, r, cv); err == nil {
-			r.WithContext(context.WithValue(r.Context(), m.ContextKey, identity))
+			// cookie pending second factor is authenticated only by handler verifying second factor
+			if cv.TwoFactorPending {
+				m.challenge(w, schemes, nil, nil)
+				m.notAuthenticated(w, r)
+				return
+			}
+			if m.ClaimsTransformer != nil {
+				if err = m.ClaimsTransformer(r.Context(), cv); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			var identity T
+			if identity, err = m.Factory(w, r, cv); err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), m.ContextKey, &identity))
+			}
+			if m.AfterHandler != nil {
+				m.AfterHandler(w, r, &identity)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isRejected(err error) bool {
+	return errors.Is(err, ErrInvalidCredentials) || errors.Is(err, gAuth.ErrorAuthCookieExpired)
+}
+
+func (m *mw[T]) scheme(name string) Scheme {
+	if s, ok := m.Schemes[name]; ok {
+		return s
+	}
+	if name == SchemeCookie && m.AuthConfig != nil {
+		return &CookieScheme{Config: m.AuthConfig}
+	}
+	panic("authentication: unknown scheme " + name)
+}
+
+// challenge adds WWW-Authenticate challenge of every scheme, failed scheme is challenged with its error
+func (m *mw[T]) challenge(w http.ResponseWriter, schemes []Scheme, failed Scheme, err error) {
+	for _, s := range schemes {
+		e := ErrNoCredentials
+		if s == failed {
+			e = err
 		}
-		if m.AfterHandler != nil {
-			m.AfterHandler(w, r, &identity)
+		if c := s.Challenge(e); c != "" {
+			w.Header().Add(ghttp.HeaderWWWAuthenticate, c)
 		}
-		next.ServeHTTP(w, r)
-	})
+	}
+}
+
+func (m *mw[T]) notAuthenticated(w http.ResponseWriter, r *http.Request) {
+	if m.NotAuthenticatedHandler != nil {
+		m.NotAuthenticatedHandler(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusUnauthorized)
 }
@@ -0,0 +1,136 @@
+package authentication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	gAuth "github.com/pudottapommin/golib/pkg/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthConfig(t *testing.T) *gAuth.Config {
+	t.Helper()
+	key, err := gAuth.NewSigningKey()
+	require.NoError(t, err)
+	return gAuth.NewConfig(gAuth.WithSigningKey(key))
+}
+
+func newAuthenticatedRequest(t *testing.T, cfg *gAuth.Config, cv *gAuth.CookieValue) *http.Request {
+	t.Helper()
+	w := httptest.NewRecorder()
+	require.NoError(t, cv.WriteToRequest(w, cfg))
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	return req
+}
+
+func Test_Authentication_ClaimsTransformer(t *testing.T) {
+	cfg := newTestAuthConfig(t)
+	cv := gAuth.NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Claims.AddRole("editor")
+
+	var identity gAuth.Identity
+	handler := New(
+		WithAuthConfig[gAuth.Identity](cfg),
+		WithFactory(func(_ http.ResponseWriter, _ *http.Request, cv *gAuth.CookieValue) (gAuth.Identity, error) {
+			return gAuth.NewIdentity(cv)
+		}),
+		WithClaimsTransformer[gAuth.Identity](func(_ context.Context, cv *gAuth.CookieValue) error {
+			cv.Claims.AddRole("admin")
+			return nil
+		}),
+	).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = *r.Context().Value(ContextKey).(*gAuth.Identity)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuthenticatedRequest(t, cfg, cv))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, identity)
+	require.Equal(t, cv.ID, identity.ID())
+	require.True(t, identity.HasRole("editor"))
+	require.True(t, identity.HasRole("admin"))
+}
+
+func Test_Authentication_NotAuthenticated(t *testing.T) {
+	handler := New(WithAuthConfig[gAuth.Identity](newTestAuthConfig(t))).Handler(http.HandlerFunc(emptyHandler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+type testIdentityStore map[uuid.UUID][]byte
+
+func (s testIdentityStore) FindByID(_ context.Context, id uuid.UUID) ([]byte, error) {
+	stamp, ok := s[id]
+	if !ok {
+		return nil, gAuth.ErrorIdentityNotFound
+	}
+	return stamp, nil
+}
+
+func Test_Authentication_SecurityStampChanged(t *testing.T) {
+	key, err := gAuth.NewSigningKey()
+	require.NoError(t, err)
+	store := testIdentityStore{}
+	cfg := gAuth.NewConfig(gAuth.WithSigningKey(key), gAuth.WithIdentityStore(store), gAuth.WithValidationInterval(0))
+	cv := gAuth.NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	store[cv.ID] = cv.SecurityStamp
+	handler := New(WithAuthConfig[gAuth.Identity](cfg), WithFactory(func(_ http.ResponseWriter, _ *http.Request, cv *gAuth.CookieValue) (gAuth.Identity, error) {
+		return gAuth.NewIdentity(cv)
+	})).Handler(http.HandlerFunc(emptyHandler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuthenticatedRequest(t, cfg, cv))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	store[cv.ID] = []byte("fedcba9876543210")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuthenticatedRequest(t, cfg, cv))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Len(t, w.Result().Cookies(), 1)
+	require.Negative(t, w.Result().Cookies()[0].MaxAge)
+}
+
+func emptyHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func Test_Authentication_TwoFactorPending(t *testing.T) {
+	cfg := newTestAuthConfig(t)
+	handler := New(WithAuthConfig[gAuth.Identity](cfg), WithFactory(func(_ http.ResponseWriter, _ *http.Request, cv *gAuth.CookieValue) (gAuth.Identity, error) {
+		return gAuth.NewIdentity(cv)
+	})).Handler(http.HandlerFunc(emptyHandler))
+
+	cv := gAuth.NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	w := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/login", nil)
+	require.NoError(t, gAuth.IssueTwoFactorPendingCookie(w, req, cv, cfg))
+	pending := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	pending.AddCookie(w.Result().Cookies()[0])
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, pending)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	pendingCv, err := gAuth.GetCookie(pending, cfg)
+	require.NoError(t, err)
+	require.True(t, pendingCv.TwoFactorPending)
+	w = httptest.NewRecorder()
+	require.NoError(t, gAuth.CompleteTwoFactor(w, pending, pendingCv, cfg))
+	completed := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	completed.AddCookie(w.Result().Cookies()[0])
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, completed)
+	require.Equal(t, http.StatusOK, w.Code)
+	completedCv, err := gAuth.GetCookie(completed, cfg)
+	require.NoError(t, err)
+	require.Equal(t, gAuth.AuthMethodMFA, completedCv.Claims.AuthMethod)
+}
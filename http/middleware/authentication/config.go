@@ -17,6 +17,23 @@ type (
 		Factory                 func(http.ResponseWriter, *http.Request, *gAuth.CookieValue) (T, error)
 		// Optional, Default: nil
 		AfterHandler func(http.ResponseWriter, *http.Request, *T)
+		// ClaimsTransformer enriches claims of cookie before Factory is called
+		//
+		// Optional, Default: nil
+		ClaimsTransformer gAuth.ClaimsTransformer
+		// SignOutHandler is called when security stamp of cookie no longer matches identity store
+		//
+		// Optional, Default: deletes cookie and responds as NotAuthenticatedHandler
+		SignOutHandler func(http.ResponseWriter, *http.Request)
+		// Schemes are authentication schemes by name, see HandlerFor. Scheme SchemeCookie is created from AuthConfig
+		// unless set
+		//
+		// Optional, Default: empty
+		Schemes map[string]Scheme
+		// DefaultScheme is scheme Handler authenticates with
+		//
+		// Optional, Default: SchemeCookie
+		DefaultScheme string
 	}
 )
 
@@ -29,6 +46,10 @@ func New[T gAuth.Identity](opts ...OptsFn[T]) *mw[T] {
 		AuthConfig:              nil,
 		Factory:                 nil,
 		AfterHandler:            nil,
+		ClaimsTransformer:       nil,
+		SignOutHandler:          nil,
+		Schemes:                 make(map[string]Scheme),
+		DefaultScheme:           SchemeCookie,
 	}
 	for i := range opts {
 		opts[i](m)
@@ -65,3 +86,28 @@ func WithAfterHandler[T gAuth.Identity](handler func(http.ResponseWriter, *http.
 		c.AfterHandler = handler
 	}
 }
+
+func WithClaimsTransformer[T gAuth.Identity](transformer gAuth.ClaimsTransformer) OptsFn[T] {
+	return func(c *mw[T]) {
+		c.ClaimsTransformer = transformer
+	}
+}
+
+func WithSignOutHandler[T gAuth.Identity](handler func(http.ResponseWriter, *http.Request)) OptsFn[T] {
+	return func(c *mw[T]) {
+		c.SignOutHandler = handler
+	}
+}
+
+// WithScheme registers authentication scheme under name
+func WithScheme[T gAuth.Identity](name string, scheme Scheme) OptsFn[T] {
+	return func(c *mw[T]) {
+		c.Schemes[name] = scheme
+	}
+}
+
+func WithDefaultScheme[T gAuth.Identity](name string) OptsFn[T] {
+	return func(c *mw[T]) {
+		c.DefaultScheme = name
+	}
+}
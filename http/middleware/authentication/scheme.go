@@ -0,0 +1,243 @@
+package authentication
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	ghttp "github.com/pudottapommin/golib/http"
+	gAuth "github.com/pudottapommin/golib/pkg/auth"
+	"github.com/pudottapommin/golib/pkg/auth/apikey"
+	"github.com/pudottapommin/golib/pkg/hasher"
+)
+
+type (
+	// Scheme authenticates request by single method, i.e. cookie or Authorization header
+	Scheme interface {
+		// Authenticate returns cookie value of request. Returns ErrNoCredentials when request carries no credentials
+		// of scheme and ErrInvalidCredentials when they are rejected
+		Authenticate(w http.ResponseWriter, r *http.Request) (*gAuth.CookieValue, error)
+		// Challenge returns WWW-Authenticate challenge sent on failed authentication, empty for no challenge
+		Challenge(err error) string
+		// SignOut discards credentials of request after gAuth.IsSignOutError error
+		SignOut(w http.ResponseWriter, r *http.Request) error
+	}
+
+	// CookieScheme authenticates by auth cookie, cookie is validated and renewed according to config
+	CookieScheme struct {
+		Config *gAuth.Config
+	}
+
+	// BearerScheme authenticates by `Authorization: Bearer <token>` header carrying token of gAuth.IssueToken
+	BearerScheme struct {
+		Config *gAuth.Config
+		// Optional, Default: ""
+		Realm string
+	}
+
+	// BasicUserStore looks up users of BasicScheme
+	BasicUserStore interface {
+		// FindByUsername returns password hash and cookie value of user. Returns gAuth.ErrorIdentityNotFound when
+		// user doesn't exist
+		FindByUsername(r *http.Request, username string) (hash string, cv *gAuth.CookieValue, err error)
+	}
+
+	// BasicScheme authenticates by HTTP Basic credentials, passwords are verified by hasher
+	BasicScheme struct {
+		Users BasicUserStore
+		// Optional, Default: hasher.New()
+		Hasher hasher.Hasher
+		// Optional, Default: ""
+		Realm string
+
+		once      sync.Once
+		dummyHash string
+	}
+
+	// APIKeyScheme authenticates by API key of apikey.Generate, sent in header or as `Authorization: ApiKey <key>`.
+	// Identity is of key owner, with key scopes as apikey.ClaimScope claim
+	APIKeyScheme struct {
+		Keys apikey.KeyStore
+		// Optional, Default: hasher.New()
+		Hasher hasher.Hasher
+		// Optional, Default: ghttp.HeaderXAPIKey
+		Header string
+
+		once sync.Once
+	}
+)
+
+// Names of built-in schemes
+const (
+	SchemeCookie = "cookie"
+	SchemeBearer = "bearer"
+	SchemeBasic  = "basic"
+	SchemeAPIKey = "apikey"
+)
+
+var (
+	ErrNoCredentials      = errors.New("authentication: No credentials")
+	ErrInvalidCredentials = errors.New("authentication: Invalid credentials")
+)
+
+func (s *CookieScheme) Authenticate(w http.ResponseWriter, r *http.Request) (*gAuth.CookieValue, error) {
+	cv, err := gAuth.GetCookie(r, s.Config)
+	if errors.Is(err, gAuth.ErrorAuthCookieMissing) {
+		return nil, ErrNoCredentials
+	} else if err != nil && !gAuth.IsSignOutError(err) {
+		// cookie that can't be decoded, verified or decrypted is rejected like invalid token of BearerScheme
+		return nil, errors.Join(ErrInvalidCredentials, err)
+	}
+	if err == nil {
+		_, err = gAuth.ValidateCookie(r.Context(), w, cv, s.Config)
+	}
+	if err == nil {
+		_, err = gAuth.RenewCookie(r.Context(), w, cv, s.Config)
+	}
+	return cv, err
+}
+
+func (s *CookieScheme) Challenge(error) string {
+	return ""
+}
+
+func (s *CookieScheme) SignOut(w http.ResponseWriter, r *http.Request) error {
+	return gAuth.DeleteCookie(r, w, s.Config)
+}
+
+func (s *BearerScheme) Authenticate(_ http.ResponseWriter, r *http.Request) (*gAuth.CookieValue, error) {
+	token, ok := authorization(r, "Bearer")
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	cv, err := gAuth.ReadToken(r.Context(), token, s.Config)
+	if err == nil {
+		_, err = gAuth.ValidateToken(r.Context(), cv, s.Config)
+	}
+	if err != nil && !gAuth.IsSignOutError(err) {
+		return nil, errors.Join(ErrInvalidCredentials, err)
+	}
+	return cv, err
+}
+
+// Challenge follows RFC 6750, rejected token is reported as invalid_token
+func (s *BearerScheme) Challenge(err error) string {
+	var params []string
+	if s.Realm != "" {
+		params = append(params, "realm="+strconv.Quote(s.Realm))
+	}
+	if err != nil && !errors.Is(err, ErrNoCredentials) {
+		params = append(params, `error="invalid_token"`)
+	}
+	return challenge("Bearer", params)
+}
+
+func (s *BearerScheme) SignOut(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+func (s *BasicScheme) Authenticate(_ http.ResponseWriter, r *http.Request) (*gAuth.CookieValue, error) {
+	credentials, ok := authorization(r, "Basic")
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	h := s.hasher()
+	hash, cv, err := s.Users.FindByUsername(r, username)
+	if errors.Is(err, gAuth.ErrorIdentityNotFound) {
+		// verify against dummy hash, so that response time doesn't reveal whether user exists
+		_, _ = h.Verify(s.dummyHash, password)
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+	result, err := h.Verify(hash, password)
+	if err != nil || result == hasher.PasswordVerificationFailed {
+		return nil, ErrInvalidCredentials
+	}
+	return cv, nil
+}
+
+func (s *BasicScheme) Challenge(error) string {
+	var params []string
+	if s.Realm != "" {
+		params = append(params, "realm="+strconv.Quote(s.Realm))
+	}
+	return challenge("Basic", append(params, `charset="UTF-8"`))
+}
+
+func (s *BasicScheme) SignOut(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+func (s *BasicScheme) hasher() hasher.Hasher {
+	s.once.Do(func() {
+		if s.Hasher == nil {
+			s.Hasher = hasher.New()
+		}
+		s.dummyHash, _ = s.Hasher.Hash("dummy password")
+	})
+	return s.Hasher
+}
+
+func (s *APIKeyScheme) Authenticate(_ http.ResponseWriter, r *http.Request) (*gAuth.CookieValue, error) {
+	s.once.Do(func() {
+		if s.Hasher == nil {
+			s.Hasher = hasher.New()
+		}
+		if s.Header == "" {
+			s.Header = ghttp.HeaderXAPIKey
+		}
+	})
+	key := strings.TrimSpace(r.Header.Get(s.Header))
+	if key == "" {
+		var ok bool
+		if key, ok = authorization(r, "ApiKey"); !ok {
+			return nil, ErrNoCredentials
+		}
+	}
+	k, err := apikey.Authenticate(r.Context(), s.Keys, s.Hasher, key)
+	if errors.Is(err, apikey.ErrKeyNotFound) || errors.Is(err, apikey.ErrKeyExpired) || errors.Is(err, apikey.ErrKeyMalformed) {
+		return nil, errors.Join(ErrInvalidCredentials, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return k.CookieValue(), nil
+}
+
+func (s *APIKeyScheme) Challenge(error) string {
+	return ""
+}
+
+func (s *APIKeyScheme) SignOut(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+// authorization returns credentials of Authorization header of scheme, scheme is case-insensitive
+func authorization(r *http.Request, scheme string) (string, bool) {
+	header := r.Header.Get(ghttp.HeaderAuthorization)
+	name, credentials, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(name, scheme) {
+		return "", false
+	}
+	credentials = strings.TrimSpace(credentials)
+	return credentials, credentials != ""
+}
+
+func challenge(scheme string, params []string) string {
+	if len(params) == 0 {
+		return scheme
+	}
+	return scheme + " " + strings.Join(params, ", ")
+}
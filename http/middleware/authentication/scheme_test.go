@@ -0,0 +1,244 @@
+package authentication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	ghttp "github.com/pudottapommin/golib/http"
+	gAuth "github.com/pudottapommin/golib/pkg/auth"
+	"github.com/pudottapommin/golib/pkg/auth/apikey"
+	"github.com/pudottapommin/golib/pkg/hasher"
+	"github.com/stretchr/testify/require"
+)
+
+type testBasicUser struct {
+	hash string
+	cv   *gAuth.CookieValue
+}
+
+type testBasicUserStore map[string]testBasicUser
+
+func (s testBasicUserStore) FindByUsername(_ *http.Request, username string) (string, *gAuth.CookieValue, error) {
+	u, ok := s[username]
+	if !ok {
+		return "", nil, gAuth.ErrorIdentityNotFound
+	}
+	return u.hash, u.cv, nil
+}
+
+func newSchemeHandler(t *testing.T, cfg *gAuth.Config, schemes ...string) (http.Handler, *gAuth.CookieValue) {
+	t.Helper()
+	h := hasher.New()
+	hash, err := h.Hash("password")
+	require.NoError(t, err)
+	cv := gAuth.NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+
+	m := New(
+		WithAuthConfig[gAuth.Identity](cfg),
+		WithFactory(func(_ http.ResponseWriter, _ *http.Request, cv *gAuth.CookieValue) (gAuth.Identity, error) {
+			return gAuth.NewIdentity(cv)
+		}),
+		WithScheme[gAuth.Identity](SchemeBearer, &BearerScheme{Config: cfg, Realm: "api"}),
+		WithScheme[gAuth.Identity](SchemeBasic, &BasicScheme{
+			Users:  testBasicUserStore{"username": {hash: hash, cv: cv}},
+			Hasher: h,
+		}),
+	)
+	return m.HandlerFor(schemes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := *r.Context().Value(ContextKey).(*gAuth.Identity)
+		_, _ = w.Write([]byte(identity.Username()))
+	})), cv
+}
+
+func Test_Scheme_Bearer(t *testing.T) {
+	t.Parallel()
+	cfg := newTestAuthConfig(t)
+	handler, cv := newSchemeHandler(t, cfg, SchemeBearer)
+	token, err := gAuth.IssueToken(t.Context(), nil, cv, cfg)
+	require.NoError(t, err)
+
+	pairs := []struct {
+		name          string
+		authorization string
+		code          int
+		challenge     string
+	}{
+		{"valid", "Bearer " + token, http.StatusOK, ""},
+		{"case insensitive", "bearer " + token, http.StatusOK, ""},
+		{"missing", "", http.StatusUnauthorized, `Bearer realm="api"`},
+		{"invalid", "Bearer invalid", http.StatusUnauthorized, `Bearer realm="api", error="invalid_token"`},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			if p.authorization != "" {
+				req.Header.Set(ghttp.HeaderAuthorization, p.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, p.code, w.Code)
+			require.Equal(t, p.challenge, w.Header().Get(ghttp.HeaderWWWAuthenticate))
+		})
+	}
+}
+
+func Test_Scheme_Basic(t *testing.T) {
+	t.Parallel()
+	handler, _ := newSchemeHandler(t, newTestAuthConfig(t), SchemeBasic)
+
+	pairs := []struct {
+		name     string
+		username string
+		password string
+		code     int
+	}{
+		{"valid", "username", "password", http.StatusOK},
+		{"wrong password", "username", "wrong", http.StatusUnauthorized},
+		{"unknown user", "unknown", "password", http.StatusUnauthorized},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			req.SetBasicAuth(p.username, p.password)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, p.code, w.Code)
+			if p.code == http.StatusOK {
+				require.Equal(t, p.username, w.Body.String())
+			} else {
+				require.Equal(t, `Basic charset="UTF-8"`, w.Header().Get(ghttp.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func Test_Scheme_AllowedOnRoute(t *testing.T) {
+	t.Parallel()
+	cfg := newTestAuthConfig(t)
+	handler, cv := newSchemeHandler(t, cfg, SchemeBearer, SchemeBasic)
+
+	// cookie is not allowed on route
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuthenticatedRequest(t, cfg, cv))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, []string{`Bearer realm="api"`, `Basic charset="UTF-8"`}, w.Header().Values(ghttp.HeaderWWWAuthenticate))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	req.SetBasicAuth("username", "password")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// default scheme is cookie
+	defaultHandler, _ := newSchemeHandler(t, cfg)
+	w = httptest.NewRecorder()
+	defaultHandler.ServeHTTP(w, newAuthenticatedRequest(t, cfg, cv))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_Scheme_InvalidCookie(t *testing.T) {
+	t.Parallel()
+	cfg := newTestAuthConfig(t)
+	handler, cv := newSchemeHandler(t, cfg, SchemeCookie, SchemeBearer)
+	token, err := gAuth.IssueToken(t.Context(), nil, cv, cfg)
+	require.NoError(t, err)
+
+	pairs := []struct {
+		name          string
+		authorization string
+		code          int
+	}{
+		{"garbage cookie", "", http.StatusUnauthorized},
+		{"garbage cookie with valid bearer", "Bearer " + token, http.StatusOK},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: cfg.CookieName(), Value: "garbage"})
+			if p.authorization != "" {
+				req.Header.Set(ghttp.HeaderAuthorization, p.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, p.code, w.Code)
+			if p.code == http.StatusOK {
+				require.Equal(t, cv.Username, w.Body.String())
+			} else {
+				require.NotContains(t, w.Body.String(), "auth:")
+				require.Equal(t, `Bearer realm="api"`, w.Header().Get(ghttp.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func Test_Scheme_UnknownPanics(t *testing.T) {
+	t.Parallel()
+	require.Panics(t, func() {
+		New[gAuth.Identity]().HandlerFor("unknown")
+	})
+}
+
+type testKeyStore map[string]*apikey.Key
+
+func (s testKeyStore) FindKey(_ context.Context, id string) (*apikey.Key, error) {
+	k, ok := s[id]
+	if !ok {
+		return nil, apikey.ErrKeyNotFound
+	}
+	return k, nil
+}
+
+func Test_Scheme_APIKey(t *testing.T) {
+	t.Parallel()
+	h := hasher.New()
+	key, k, err := apikey.Generate(h, "sk", uuid.Must(uuid.NewV4()), []string{"read"}, time.Time{})
+	require.NoError(t, err)
+	k.OwnerName = "service"
+
+	m := New(
+		WithFactory(func(_ http.ResponseWriter, _ *http.Request, cv *gAuth.CookieValue) (gAuth.Identity, error) {
+			return gAuth.NewIdentity(cv)
+		}),
+		WithScheme[gAuth.Identity](SchemeAPIKey, &APIKeyScheme{Keys: testKeyStore{k.ID: k}, Hasher: h}),
+	)
+	handler := m.HandlerFor(SchemeAPIKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := *r.Context().Value(ContextKey).(*gAuth.Identity)
+		require.True(t, apikey.HasScope(identity, "read"))
+		_, _ = w.Write([]byte(identity.Username()))
+	}))
+
+	pairs := []struct {
+		name   string
+		header string
+		value  string
+		code   int
+	}{
+		{"header", ghttp.HeaderXAPIKey, key, http.StatusOK},
+		{"authorization", ghttp.HeaderAuthorization, "ApiKey " + key, http.StatusOK},
+		{"missing", "", "", http.StatusUnauthorized},
+		{"invalid", ghttp.HeaderXAPIKey, key[:len(key)-1] + "_", http.StatusUnauthorized},
+		{"malformed", ghttp.HeaderXAPIKey, "invalid", http.StatusUnauthorized},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			if p.header != "" {
+				req.Header.Set(p.header, p.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, p.code, w.Code)
+			if p.code == http.StatusOK {
+				require.Equal(t, "service", w.Body.String())
+			}
+		})
+	}
+}
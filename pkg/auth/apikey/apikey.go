@@ -0,0 +1,142 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/auth"
+	"github.com/pudottapommin/golib/pkg/hasher"
+	long "github.com/pudottapommin/golib/pkg/id/long"
+	short "github.com/pudottapommin/golib/pkg/id/short"
+)
+
+type (
+	// Key is stored API key. Secret of key is shown once on generation, only its hash is stored.
+	Key struct {
+		// ID identifies key in store, it is part of key and not secret
+		ID string
+		// Prefix is visible prefix of key, i.e. `sk_live`, telling what key is for
+		Prefix string
+		// Hash is pkg/hasher hash of secret
+		Hash      string
+		OwnerID   uuid.UUID
+		OwnerName string
+		// Name describes key to its owner, i.e. `CI deploy`
+		Name   string
+		Scopes []string
+		// ExpiresAt is expiration of key, zero means key doesn't expire
+		ExpiresAt time.Time
+		CreatedAt time.Time
+	}
+
+	// KeyStore looks up API keys, i.e. from database
+	KeyStore interface {
+		// FindKey returns key by its ID. Returns ErrKeyNotFound when key doesn't exist or was revoked
+		FindKey(ctx context.Context, id string) (*Key, error)
+	}
+)
+
+const (
+	// AuthMethodAPIKey is auth method of identities authenticated by API key
+	AuthMethodAPIKey = "apikey"
+	// ClaimScope holds space separated scopes of key
+	ClaimScope = "scope"
+	// ClaimKeyID holds ID of key
+	ClaimKeyID = "key_id"
+
+	idSize     = 12
+	secretSize = 32
+)
+
+var (
+	ErrKeyNotFound  = errors.New("apikey: Key not found")
+	ErrKeyExpired   = errors.New("apikey: Key has expired")
+	ErrKeyMalformed = errors.New("apikey: Malformed key")
+)
+
+// Generate generates key `<prefix>_<id>_<secret>` for owner. Returned key must be shown to owner once,
+// returned Key carrying only hash of secret is to be stored.
+func Generate(h hasher.Hasher, prefix string, ownerID uuid.UUID, scopes []string, expiresAt time.Time) (string, *Key, error) {
+	if prefix == "" {
+		return "", nil, ErrKeyMalformed
+	}
+	id := short.New().String()
+	secret := long.New().String()
+	hash, err := h.Hash(secret)
+	if err != nil {
+		return "", nil, err
+	}
+	k := &Key{
+		ID:        id,
+		Prefix:    prefix,
+		Hash:      hash,
+		OwnerID:   ownerID,
+		Scopes:    slices.Clone(scopes),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	return prefix + "_" + id + "_" + secret, k, nil
+}
+
+// Parse splits key into its prefix, ID and secret
+func Parse(key string) (prefix, id, secret string, err error) {
+	if len(key) < idSize+secretSize+3 {
+		return "", "", "", ErrKeyMalformed
+	}
+	secretAt := len(key) - secretSize
+	idAt := secretAt - 1 - idSize
+	if key[secretAt-1] != '_' || key[idAt-1] != '_' {
+		return "", "", "", ErrKeyMalformed
+	}
+	return key[:idAt-1], key[idAt : secretAt-1], key[secretAt:], nil
+}
+
+// Authenticate verifies key against its stored hash and returns stored key. Returns ErrKeyNotFound when key
+// doesn't match and ErrKeyExpired when it expired. Key ID is not secret, so unknown IDs are not hidden by timing.
+func Authenticate(ctx context.Context, store KeyStore, h hasher.Hasher, key string) (*Key, error) {
+	prefix, id, secret, err := Parse(key)
+	if err != nil {
+		return nil, err
+	}
+	k, err := store.FindKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.Prefix != prefix {
+		return nil, ErrKeyNotFound
+	}
+	result, err := h.Verify(k.Hash, secret)
+	if err != nil || result == hasher.PasswordVerificationFailed {
+		return nil, ErrKeyNotFound
+	}
+	if !k.ExpiresAt.IsZero() && !time.Now().Before(k.ExpiresAt) {
+		return nil, ErrKeyExpired
+	}
+	return k, nil
+}
+
+// HasScope reports whether key grants scope
+func (k *Key) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// CookieValue returns cookie value of key owner with scopes and key ID as claims, for identity factory
+// of authentication middleware
+func (k *Key) CookieValue() *auth.CookieValue {
+	cv := auth.NewCookieValue(k.OwnerID, k.OwnerName, nil)
+	cv.Timestamp = k.ExpiresAt
+	cv.Claims.AuthMethod = AuthMethodAPIKey
+	cv.Claims.SetClaim(ClaimKeyID, k.ID)
+	cv.Claims.SetClaim(ClaimScope, strings.Join(k.Scopes, " "))
+	return cv
+}
+
+// HasScope reports whether identity authenticated by API key has scope
+func HasScope(identity auth.Identity, scope string) bool {
+	scopes, _ := identity.Claim(ClaimScope)
+	return slices.Contains(strings.Fields(scopes), scope)
+}
@@ -0,0 +1,108 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/auth"
+	"github.com/pudottapommin/golib/pkg/hasher"
+)
+
+type testKeyStore map[string]*Key
+
+func (s testKeyStore) FindKey(_ context.Context, id string) (*Key, error) {
+	k, ok := s[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return k, nil
+}
+
+func TestGenerateParse(t *testing.T) {
+	h := hasher.New()
+	key, k, err := Generate(h, "sk_live", uuid.Must(uuid.NewV4()), []string{"read"}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "sk_live_") {
+		t.Errorf("key %q is missing prefix", key)
+	}
+	if strings.Contains(k.Hash, key[len(key)-secretSize:]) {
+		t.Error("stored key contains secret")
+	}
+
+	prefix, id, secret, err := Parse(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix != "sk_live" || id != k.ID || len(secret) != secretSize {
+		t.Errorf("Parse() = %q, %q, %q", prefix, id, secret)
+	}
+
+	for _, malformed := range []string{"", "sk_live", strings.Replace(key, "_", "-", -1)} {
+		if _, _, _, err = Parse(malformed); !errors.Is(err, ErrKeyMalformed) {
+			t.Errorf("Parse(%q) error = %v, want ErrKeyMalformed", malformed, err)
+		}
+	}
+	if _, _, err = Generate(h, "", uuid.Nil, nil, time.Time{}); !errors.Is(err, ErrKeyMalformed) {
+		t.Errorf("Generate() without prefix error = %v", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	h := hasher.New()
+	ownerID := uuid.Must(uuid.NewV4())
+	key, k, err := Generate(h, "sk", ownerID, []string{"read", "write"}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.OwnerName = "service"
+	expiredKey, expired, err := Generate(h, "sk", ownerID, nil, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := testKeyStore{k.ID: k, expired.ID: expired}
+
+	got, err := Authenticate(t.Context(), store, h, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != k || !got.HasScope("write") || got.HasScope("admin") {
+		t.Errorf("Authenticate() = %+v", got)
+	}
+
+	pairs := []struct {
+		name string
+		key  string
+		err  error
+	}{
+		{"wrong secret", key[:len(key)-1] + "_", ErrKeyNotFound},
+		{"wrong prefix", "pk" + key[2:], ErrKeyNotFound},
+		{"unknown id", "sk_" + strings.Repeat("a", idSize) + key[len(key)-secretSize-1:], ErrKeyNotFound},
+		{"expired", expiredKey, ErrKeyExpired},
+		{"malformed", "sk_invalid", ErrKeyMalformed},
+	}
+	for _, p := range pairs {
+		if _, err = Authenticate(t.Context(), store, h, p.key); !errors.Is(err, p.err) {
+			t.Errorf("%s: Authenticate() error = %v, want %v", p.name, err, p.err)
+		}
+	}
+
+	identity, err := auth.NewIdentity(k.CookieValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.ID() != ownerID || identity.Username() != "service" || identity.Claims().AuthMethod != AuthMethodAPIKey {
+		t.Errorf("identity = %+v", identity)
+	}
+	if !HasScope(identity, "read") || HasScope(identity, "admin") {
+		t.Error("HasScope() doesn't match key scopes")
+	}
+	if id, _ := identity.Claim(ClaimKeyID); id != k.ID {
+		t.Errorf("key ID claim = %q, want %q", id, k.ID)
+	}
+}
@@ -2,30 +2,63 @@ package auth
 
 import (
 	"crypto/ecdsa"
-	"crypto/rand"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
+	"net/http"
 	"slices"
 	"time"
 )
 
 type (
-	OptFn  func(*Config)
-	Config struct {
-		cookieName string
-		signingKey *ecdsa.PrivateKey
+	OptFn func(*Config)
+	// CookiePrefix is prefix of cookie name browsers enforce attributes of, see WithCookiePrefix
+	CookiePrefix string
+	Config       struct {
+		cookieName   string
+		cookiePrefix CookiePrefix
+		cookieDomain string
+		cookiePath   string
+		sameSite     http.SameSite
+		secure       bool
+		keyring      *Keyring
+		// keyringErr is error of option which failed to set keyring, reported when token is signed
+		keyringErr error
+		// encryption enables encrypted tokens when set
+		encryption *EncryptionKeyring
 		expiration time.Duration
 		isSliding  bool
+		// renewThreshold is remaining lifetime below which sliding cookie is re-issued, 0 means half of expiration
+		renewThreshold time.Duration
+		// maxLifetime is absolute lifetime of cookie counted from sign in, 0 means sliding cookie is renewed forever
+		maxLifetime time.Duration
+		// identityStore enables periodic security stamp validation when set
+		identityStore IdentityStore
+		// validationInterval is how often security stamp is validated against identityStore
+		validationInterval time.Duration
+		// twoFactorTimeout is lifetime of cookie pending second factor
+		twoFactorTimeout time.Duration
+		// ticketStore keeps cookie values server-side when set
+		ticketStore TicketStore
+		// legacyTokens accepts legacy base64 JSON tokens, whose signature doesn't cover every field
+		legacyTokens bool
+		clock        func() time.Time
 	}
 )
 
 func NewConfig(opts ...OptFn) *Config {
 	cfg := &Config{
 		cookieName: "auth",
-		signingKey: nil,
+		cookiePath: "/",
+		sameSite:   http.SameSiteStrictMode,
+		secure:     true,
+		keyring:    nil,
 		expiration: time.Hour * 8,
 		isSliding:  false,
+		clock:      time.Now,
+
+		legacyTokens: true,
+
+		validationInterval: time.Minute * 30,
+		twoFactorTimeout:   time.Minute * 5,
 	}
 
 	for _, o := range opts {
@@ -35,6 +68,14 @@ func NewConfig(opts ...OptFn) *Config {
 	return cfg
 }
 
+const (
+	CookiePrefixNone CookiePrefix = ""
+	// CookiePrefixSecure requires cookie to be Secure
+	CookiePrefixSecure CookiePrefix = "__Secure-"
+	// CookiePrefixHost requires cookie to be Secure, with Path `/` and without Domain
+	CookiePrefixHost CookiePrefix = "__Host-"
+)
+
 var (
 	ErrSecurityStampNotMatching = errors.New("auth: Security stamp doesn't match")
 	ErrKeyNotVerified           = errors.New("auth: Unable to verify auth token")
@@ -42,12 +83,40 @@ var (
 	ErrorSecurityStampsDiffer   = errors.New("auth: Security stamps don't match")
 )
 
+// SigningKey returns active signing key when it is ECDSA key
 func (c *Config) SigningKey() *ecdsa.PrivateKey {
-	return c.signingKey
+	if c.keyring == nil {
+		return nil
+	}
+	key, _ := c.keyring.active.signer.(*ecdsa.PrivateKey)
+	return key
 }
 
 func (c *Config) SigningKeyPublic() *ecdsa.PublicKey {
-	return c.signingKey.Public().(*ecdsa.PublicKey)
+	if key := c.SigningKey(); key != nil {
+		return &key.PublicKey
+	}
+	return nil
+}
+
+// CookieName returns name of auth cookie including its prefix
+func (c *Config) CookieName() string {
+	return string(c.cookiePrefix) + c.cookieName
+}
+
+func (c *Config) Keyring() *Keyring {
+	return c.keyring
+}
+
+// signingKeyring returns keyring tokens are signed with, or error of option which failed to set it
+func (c *Config) signingKeyring() (*Keyring, error) {
+	if c.keyringErr != nil {
+		return nil, c.keyringErr
+	}
+	if c.keyring == nil {
+		return nil, ErrNoSigningKey
+	}
+	return c.keyring, nil
 }
 
 func WithCookieName(name string) func(*Config) {
@@ -56,9 +125,68 @@ func WithCookieName(name string) func(*Config) {
 	}
 }
 
+// WithCookiePrefix prefixes cookie name, making browser reject cookie which lacks attributes required by prefix.
+// Attributes required by prefix take precedence over WithSecure, WithCookiePath and WithCookieDomain.
+func WithCookiePrefix(prefix CookiePrefix) func(*Config) {
+	return func(c *Config) {
+		c.cookiePrefix = prefix
+	}
+}
+
+// WithCookieDomain sets Domain of cookie, making it available to subdomains. Defaults to host of request only
+func WithCookieDomain(domain string) func(*Config) {
+	return func(c *Config) {
+		c.cookieDomain = domain
+	}
+}
+
+// WithCookiePath sets Path of cookie. Defaults to `/`
+func WithCookiePath(path string) func(*Config) {
+	return func(c *Config) {
+		c.cookiePath = path
+	}
+}
+
+// WithSameSite sets SameSite of cookie. Defaults to http.SameSiteStrictMode
+func WithSameSite(sameSite http.SameSite) func(*Config) {
+	return func(c *Config) {
+		c.sameSite = sameSite
+	}
+}
+
+// WithSecure sets whether cookie is sent over HTTPS only. Defaults to true, disable only for local development
+func WithSecure(secure bool) func(*Config) {
+	return func(c *Config) {
+		c.secure = secure
+	}
+}
+
+// WithSigningKey sets keyring containing only key. Nil key leaves config without keyring, signing then fails
+// with ErrNoSigningKey
 func WithSigningKey(key *ecdsa.PrivateKey) func(*Config) {
 	return func(c *Config) {
-		c.signingKey = key
+		k, err := NewKey(key)
+		if err != nil {
+			c.keyring, c.keyringErr = nil, err
+			return
+		}
+		c.keyring, c.keyringErr = NewKeyring(k)
+	}
+}
+
+// WithKeyring sets keyring, allowing signing key to be rotated without invalidating tokens signed by previous keys
+func WithKeyring(kr *Keyring) func(*Config) {
+	return func(c *Config) {
+		c.keyringErr = nil
+		c.keyring = kr
+	}
+}
+
+// WithLegacyTokens sets whether legacy base64 JSON tokens issued before binary tokens are accepted. Defaults to
+// true, disable once every legacy cookie had chance to be re-issued, i.e. after expiration or max lifetime
+func WithLegacyTokens(accept bool) func(*Config) {
+	return func(c *Config) {
+		c.legacyTokens = accept
 	}
 }
 
@@ -74,39 +202,77 @@ func IsSliding() func(*Config) {
 	}
 }
 
-func ValidateSecurityStamp(a, b []byte) bool {
-	return slices.Equal(a, b)
+// WithEncryption encrypts tokens with active key of keyring, so that cookie content can't be read by client.
+// Tokens remain signed by signing keyring.
+func WithEncryption(kr *EncryptionKeyring) func(*Config) {
+	return func(c *Config) {
+		c.encryption = kr
+	}
+}
+
+// WithIdentityStore enables validation of cookie security stamp against store, see ValidateCookie
+func WithIdentityStore(store IdentityStore) func(*Config) {
+	return func(c *Config) {
+		c.identityStore = store
+	}
 }
 
-func encodeAuthToken(key *ecdsa.PrivateKey, cv *CookieValue) (token string, err error) {
-	signedBytes, err := key.Sign(rand.Reader, cv.Digest(), nil)
-	if err != nil {
-		return
+// WithTicketStore keeps cookie values in store, cookie holds only opaque session ID of its ticket. Sessions can
+// then be listed and revoked, see ListSessions and RevokeSession.
+func WithTicketStore(store TicketStore) func(*Config) {
+	return func(c *Config) {
+		c.ticketStore = store
 	}
+}
 
-	cv.Signature = signedBytes
+// WithValidationInterval sets how often security stamp is validated against identity store. Defaults to 30 minutes,
+// 0 validates every request
+func WithValidationInterval(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.validationInterval = d
+	}
+}
 
-	jsonBytes, err := json.Marshal(cv)
-	if err != nil {
-		return
+// WithRenewThreshold sets remaining lifetime below which sliding cookie is re-issued. Defaults to half of expiration
+func WithRenewThreshold(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.renewThreshold = d
 	}
+}
 
-	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+// WithMaxLifetime limits how long sliding cookie can be renewed, counted from the time it was first issued
+func WithMaxLifetime(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.maxLifetime = d
+	}
 }
 
-func decodeAuthToken(key *ecdsa.PrivateKey, s string) (cv *CookieValue, err error) {
-	decoded, err := base64.StdEncoding.DecodeString(s)
-	if err != nil {
-		return
+// WithTwoFactorTimeout sets how long user has to enter second factor after signing in with password.
+// Defaults to 5 minutes
+func WithTwoFactorTimeout(d time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.twoFactorTimeout = d
 	}
+}
 
-	if err = json.Unmarshal(decoded, &cv); err != nil {
-		return
+// WithClock replaces time.Now as source of current time
+func WithClock(clock func() time.Time) func(*Config) {
+	return func(c *Config) {
+		c.clock = clock
 	}
+}
+
+func (c *Config) now() time.Time {
+	return c.clock().UTC()
+}
 
-	if !ecdsa.VerifyASN1(&key.PublicKey, cv.Digest(), cv.Signature) {
-		return cv, ErrKeyNotVerified
+func (c *Config) renewAfter() time.Duration {
+	if c.renewThreshold > 0 {
+		return c.renewThreshold
 	}
+	return c.expiration / 2
+}
 
-	return
+func ValidateSecurityStamp(a, b []byte) bool {
+	return slices.Equal(a, b)
 }
@@ -3,9 +3,9 @@ package auth
 import "crypto/ecdsa"
 
 func EncodeAuthToken(key *ecdsa.PrivateKey, cv *CookieValue) (token string, err error) {
-	return encodeAuthToken(key, cv)
+	return encodeAuthToken(NewConfig(WithSigningKey(key)).keyring, cv)
 }
 
 func DecodeAuthToken(key *ecdsa.PrivateKey, s string) (cv *CookieValue, err error) {
-	return decodeAuthToken(key, s)
+	return decodeAuthToken(NewConfig(WithSigningKey(key)).keyring, s, true)
 }
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// IssueToken issues token for Authorization header, i.e. `Bearer <token>`. Token has same format and lifetime
+// as cookie, with TicketStore set it is session ID of ticket.
+func IssueToken(ctx context.Context, r *http.Request, cv *CookieValue, cfg *Config) (string, error) {
+	cv.setLifetime(cfg)
+	return cv.token(ctx, r, cfg)
+}
+
+// ReadToken reads token issued by IssueToken or held by cookie. Returns ErrorAuthCookieExpired when token expired.
+func ReadToken(ctx context.Context, token string, cfg *Config) (*CookieValue, error) {
+	return cfg.readToken(ctx, token)
+}
+
+// token encodes cv, with TicketStore set cv is stored in ticket and token is its session ID.
+// Request describes device of new ticket and may be nil.
+func (cv *CookieValue) token(ctx context.Context, r *http.Request, cfg *Config) (string, error) {
+	if cfg.ticketStore == nil {
+		return cfg.encodeToken(cv)
+	}
+	if err := cv.storeTicket(ctx, r, cfg); err != nil {
+		return "", err
+	}
+	return cv.ticket.SessionID, nil
+}
+
+func (c *Config) readToken(ctx context.Context, token string) (cv *CookieValue, err error) {
+	if c.ticketStore != nil && isSessionID(token) {
+		cv, err = c.readTicket(ctx, token)
+	} else {
+		cv, err = c.decodeToken(token)
+	}
+	if err == nil && !c.now().Before(cv.Timestamp) {
+		return cv, ErrorAuthCookieExpired
+	}
+	return
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestIssueToken(t *testing.T) {
+	ctx := context.Background()
+	for name, withTickets := range map[string]bool{"signed": false, "ticket": true} {
+		t.Run(name, func(t *testing.T) {
+			cfg, clock := newTestConfig(t)
+			if withTickets {
+				WithTicketStore(NewMemoryTicketStore(WithTicketClock(clock.Now)))(cfg)
+			}
+			cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+			token, err := IssueToken(ctx, nil, cv, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadToken(ctx, token, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != cv.ID || !got.Timestamp.Equal(clock.now.Add(time.Hour)) {
+				t.Errorf("unexpected token value %+v", got)
+			}
+
+			clock.Advance(time.Hour)
+			// store evicts expired ticket
+			if _, err = ReadToken(ctx, token, cfg); !errors.Is(err, ErrorAuthCookieExpired) && !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("expected expired token, got %v", err)
+			}
+		})
+	}
+}
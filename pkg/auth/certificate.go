@@ -1,11 +1,24 @@
 package auth
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
 )
 
+var ErrNoKeysFound = errors.New("auth: No keys found")
+
 func NewSigningKey() (*ecdsa.PrivateKey, error) {
 	return NewSigningKeyCurve(elliptic.P521())
 }
@@ -13,3 +26,125 @@ func NewSigningKey() (*ecdsa.PrivateKey, error) {
 func NewSigningKeyCurve(c elliptic.Curve) (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(c, rand.Reader)
 }
+
+func NewSigningKeyEd25519() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
+// ParseKeyPEM parses first PEM block of data. `EC PRIVATE KEY`, `PRIVATE KEY` (PKCS #8) and `PUBLIC KEY` (PKIX)
+// blocks are supported, public key becomes verification-only key.
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoKeysFound
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: PEM block %s", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(key)
+}
+
+// LoadKeyFile loads key from PEM file, see ParseKeyPEM
+func LoadKeyFile(name string) (*Key, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	k, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to load key %s: %w", name, err)
+	}
+	return k, nil
+}
+
+// LoadKeyringFiles loads keyring signing with key in active file and verifying also with keys in verify files
+func LoadKeyringFiles(active string, verify ...string) (*Keyring, error) {
+	activeKey, err := LoadKeyFile(active)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*Key, len(verify))
+	for i := range verify {
+		if keys[i], err = LoadKeyFile(verify[i]); err != nil {
+			return nil, err
+		}
+	}
+	return NewKeyring(activeKey, keys...)
+}
+
+// LoadKeyringDir loads every `*.pem` file in dir. Private key of file last in lexical order is active,
+// which is the newest key when files are written by WriteKeyFile. Other keys are used for verification only.
+func LoadKeyringDir(dir string) (*Keyring, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	keys := make([]*Key, 0, len(files))
+	var active *Key
+	for _, file := range files {
+		k, err := LoadKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if k.CanSign() {
+			active = k
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKeysFound
+	}
+	return NewKeyring(active, keys...)
+}
+
+// WriteKeyFile persists private key as PKCS #8 PEM file named `<unix nano time>-<key ID>.pem` in dir,
+// so that LoadKeyringDir picks it as active key
+func WriteKeyFile(dir string, key crypto.Signer) (string, error) {
+	k, err := NewKey(key)
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, strconv.FormatInt(time.Now().UnixNano(), 10)+"-"+k.ID()+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err = os.WriteFile(name, data, 0o600); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// GenerateKeyFile generates new signing key with NewSigningKey and persists it with WriteKeyFile
+func GenerateKeyFile(dir string) (*Key, string, error) {
+	key, err := NewSigningKey()
+	if err != nil {
+		return nil, "", err
+	}
+	name, err := WriteKeyFile(dir, key)
+	if err != nil {
+		return nil, "", err
+	}
+	k, err := NewKey(key)
+	return k, name, err
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+type (
+	// ClaimSet holds claims carried in auth cookie. Embedding it into Identity implementation provides
+	// HasRole, Claim and Claims methods
+	ClaimSet struct {
+		Roles    []string
+		TenantID string
+		// AuthMethod is how user authenticated, i.e. AuthMethodPassword
+		AuthMethod string
+		// Values holds arbitrary string claims
+		Values map[string]string
+	}
+
+	// ClaimsTransformer enriches claims of authenticated cookie, i.e. from database. Changes are not persisted
+	// to cookie, transformer runs on every authenticated request
+	ClaimsTransformer func(ctx context.Context, cv *CookieValue) error
+)
+
+// Authentication methods, values follow RFC 8176
+const (
+	AuthMethodPassword    = "pwd"
+	AuthMethodOTP         = "otp"
+	AuthMethodMFA         = "mfa"
+	AuthMethodHardwareKey = "hwk"
+)
+
+func (cs ClaimSet) HasRole(role string) bool {
+	return slices.Contains(cs.Roles, role)
+}
+
+// Claim returns value of arbitrary string claim
+func (cs ClaimSet) Claim(name string) (string, bool) {
+	v, ok := cs.Values[name]
+	return v, ok
+}
+
+func (cs ClaimSet) Claims() ClaimSet {
+	return cs
+}
+
+// IsZero reports whether claim set has no claims
+func (cs ClaimSet) IsZero() bool {
+	return len(cs.Roles) == 0 && cs.TenantID == "" && cs.AuthMethod == "" && len(cs.Values) == 0
+}
+
+// Clone returns deep copy of claim set
+func (cs ClaimSet) Clone() ClaimSet {
+	cs.Roles = slices.Clone(cs.Roles)
+	cs.Values = maps.Clone(cs.Values)
+	return cs
+}
+
+// AddRole adds role unless claim set already has it
+func (cs *ClaimSet) AddRole(role string) {
+	if !cs.HasRole(role) {
+		cs.Roles = append(cs.Roles, role)
+	}
+}
+
+// SetClaim sets value of arbitrary string claim
+func (cs *ClaimSet) SetClaim(name, value string) {
+	if cs.Values == nil {
+		cs.Values = make(map[string]string)
+	}
+	cs.Values[name] = value
+}
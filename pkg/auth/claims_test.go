@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type testUser struct {
+	ClaimSet
+	id uuid.UUID
+}
+
+var _ Identity = (*testUser)(nil)
+
+func (u *testUser) ID() uuid.UUID    { return u.id }
+func (u *testUser) Username() string { return "" }
+
+func TestCookieClaims(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Claims = ClaimSet{Roles: []string{"admin", "editor"}, TenantID: "acme", AuthMethod: AuthMethodPassword}
+	cv.Claims.SetClaim("locale", "fi")
+	cv.Claims.SetClaim("plan", "pro")
+
+	w := httptest.NewRecorder()
+	if err := cv.WriteToRequest(w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := readCookie(t, cfg, w.Result().Cookies()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, _ := NewIdentity(decoded)
+	if !identity.HasRole("editor") || identity.HasRole("owner") {
+		t.Errorf("unexpected roles %v", identity.Claims().Roles)
+	}
+	if v, ok := identity.Claim("plan"); !ok || v != "pro" {
+		t.Errorf("unexpected plan claim %q", v)
+	}
+	if c := identity.Claims(); c.TenantID != "acme" || c.AuthMethod != AuthMethodPassword || len(c.Values) != 2 {
+		t.Errorf("unexpected claims %+v", c)
+	}
+}
+
+func TestLegacyTokenClaimsIgnored(t *testing.T) {
+	kr := NewConfig(WithSigningKey(getEcdsaKey(t))).keyring
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Claims.AddRole("admin")
+	token, err := signLegacyToken(kr, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeAuthToken(kr, token, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Claims.IsZero() {
+		t.Errorf("claims of legacy token are not signed, got %+v", decoded.Claims)
+	}
+}
+
+func TestCookieTooLarge(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Claims.SetClaim("large", strings.Repeat("x", maxCookieChunks*maxCookieSize))
+	if err := cv.WriteToRequest(httptest.NewRecorder(), cfg); !errors.Is(err, ErrorAuthCookieTooLarge) {
+		t.Errorf("expected ErrorAuthCookieTooLarge, got %v", err)
+	}
+}
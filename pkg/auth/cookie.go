@@ -1,29 +1,63 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
 
 var (
-	ErrorAuthCookieMissing = errors.New("auth: Cookie is missing")
+	ErrorAuthCookieMissing  = errors.New("auth: Cookie is missing")
+	ErrorAuthCookieExpired  = errors.New("auth: Cookie has expired")
+	ErrorAuthCookieTooLarge = errors.New("auth: Cookie exceeds maximum size")
+)
+
+const (
+	// maxCookieSize is size of cookie, including its name and attributes, browsers are required to support
+	maxCookieSize = 4096
+	// maxCookieChunks limits number of cookies large token is split into
+	maxCookieChunks = 10
+	// chunksPrefix starts value of cookie which holds number of chunks instead of token
+	chunksPrefix = "chunks-"
 )
 
 type CookieValue struct {
 	ID            uuid.UUID `json:"id"`
 	Username      string    `json:"username"`
 	SecurityStamp []byte    `json:"securityStamp"`
-	Timestamp     time.Time `json:"timestamp"`
-	Signature     []byte    `json:"signature"`
+	// Timestamp is expiration time of cookie
+	Timestamp time.Time `json:"timestamp"`
+	// IssuedAt is time of sign in, renewed cookies keep the original time
+	IssuedAt time.Time `json:"-"`
+	// ValidatedAt is time security stamp was last validated against IdentityStore
+	ValidatedAt time.Time `json:"-"`
+	// Claims are carried in binary tokens only
+	Claims ClaimSet `json:"-"`
+	// SessionOnly cookie is discarded when browser closes, i.e. when user didn't choose to be remembered on sign in.
+	// Cookie still expires at Timestamp.
+	SessionOnly bool `json:"-"`
+	// TwoFactorPending cookie is issued after password was verified but second factor wasn't yet, authentication
+	// middleware treats it as not authenticated. See CompleteTwoFactor
+	TwoFactorPending bool `json:"-"`
+	// KeyID identifies key of keyring cookie was signed with
+	KeyID     string `json:"kid,omitempty"`
+	Signature []byte `json:"signature"`
+	// ticket is server-side session of cookie when TicketStore is used
+	ticket *Ticket
+	// legacy is set when cookie value was decoded from legacy token, whose signature doesn't cover Username
+	legacy bool
 }
 
 func NewCookieValue(id uuid.UUID, username string, securityStamp []byte) *CookieValue {
 	return &CookieValue{ID: id, Username: username, SecurityStamp: securityStamp}
 }
 
+// Digest is signed by legacy base64 JSON tokens. It covers ID, first 16 bytes of SecurityStamp and Timestamp only
 func (cv *CookieValue) Digest() []byte {
 	bytes := make([]byte, 49)
 	copy(bytes, cv.ID[:])
@@ -34,49 +68,194 @@ func (cv *CookieValue) Digest() []byte {
 	copy(bytes[34:], tb)
 	return bytes
 }
+
 func (cv *CookieValue) WriteToRequest(w http.ResponseWriter, cfg *Config) error {
-	cv.Timestamp = time.Now().UTC().Add(cfg.expiration)
-	token, err := encodeAuthToken(cfg.SigningKey(), cv)
-	if err != nil {
-		return err
-	}
+	return cv.issue(context.Background(), w, nil, cfg)
+}
 
-	cookie := new(http.Cookie)
-	cookie.Name = cfg.cookieName
-	cookie.Value = token
-	cookie.HttpOnly = true
-	cookie.Secure = true
-	cookie.SameSite = http.SameSiteStrictMode
-	cookie.Expires = cv.Timestamp
-	http.SetCookie(w, cookie)
-	return nil
+// IssueCookie writes cookie like WriteToRequest. With TicketStore set, user agent and IP address of request are
+// recorded in ticket of new session.
+func IssueCookie(w http.ResponseWriter, r *http.Request, cv *CookieValue, cfg *Config) error {
+	return cv.issue(r.Context(), w, r, cfg)
 }
 
-func GetCookie(r *http.Request, cfg *Config) (cv *CookieValue, err error) {
-	cookie, err := r.Cookie(cfg.cookieName)
+func (cv *CookieValue) issue(ctx context.Context, w http.ResponseWriter, r *http.Request, cfg *Config) error {
+	cv.setLifetime(cfg)
+	return cv.writeCookie(ctx, w, r, cfg)
+}
+
+// setLifetime sets IssuedAt on first issue and Timestamp to expiration capped by maximum lifetime
+func (cv *CookieValue) setLifetime(cfg *Config) {
+	now := cfg.now()
+	if cv.IssuedAt.IsZero() {
+		cv.IssuedAt = now
+	}
+	if cv.ValidatedAt.IsZero() {
+		cv.ValidatedAt = now
+	}
+	cv.Timestamp = now.Add(cfg.expiration)
+	if cv.TwoFactorPending && cfg.twoFactorTimeout < cfg.expiration {
+		cv.Timestamp = now.Add(cfg.twoFactorTimeout)
+	}
+	if limit := cv.IssuedAt.UTC().Add(cfg.maxLifetime); cfg.maxLifetime > 0 && limit.Before(cv.Timestamp) {
+		cv.Timestamp = limit
+	}
+}
+
+// writeCookie writes cookie keeping its current expiration. With TicketStore set, cookie value is stored in ticket
+// and cookie holds only its session ID.
+func (cv *CookieValue) writeCookie(ctx context.Context, w http.ResponseWriter, r *http.Request, cfg *Config) error {
+	token, err := cv.token(ctx, r, cfg)
 	if err != nil {
-		return cv, ErrorAuthCookieMissing
+		return err
+	}
+	var expires time.Time
+	if !cv.SessionOnly {
+		expires = cv.Timestamp
 	}
+	return cfg.setCookie(w, token, expires)
+}
 
-	err = cookie.Valid()
+func GetCookie(r *http.Request, cfg *Config) (cv *CookieValue, err error) {
+	value, err := cfg.readCookie(r)
 	if err != nil {
 		return
 	}
+	return cfg.readToken(r.Context(), value)
+}
 
-	cv, err = decodeAuthToken(cfg.SigningKey(), cookie.Value)
-	if err == nil && cfg.isSliding && cv.Timestamp.UTC().Sub(time.Now().UTC()) < (time.Minute*15) {
-		// @todo
-		// err = WithCookie(c, cfg, *cv)
+// RenewCookie re-issues sliding cookie with fresh Timestamp once its remaining lifetime falls below renew threshold.
+// Cookie is not renewed past maximum lifetime set by WithMaxLifetime. Legacy cookie is never renewed, as its
+// unsigned fields would be signed into new token, it expires and user signs in again. With TicketStore set, returns
+// ErrTicketNotFound when session was revoked after cookie was read.
+func RenewCookie(ctx context.Context, w http.ResponseWriter, cv *CookieValue, cfg *Config) (renewed bool, err error) {
+	if !cfg.isSliding || cv.TwoFactorPending || cv.legacy {
+		return false, nil
+	}
+	now := cfg.now()
+	if cv.Timestamp.Sub(now) >= cfg.renewAfter() {
+		return false, nil
+	}
+	if cfg.maxLifetime > 0 && !cv.IssuedAt.IsZero() && !cv.Timestamp.Before(cv.IssuedAt.Add(cfg.maxLifetime)) {
+		return false, nil
+	}
+	if err = cv.issue(ctx, w, nil, cfg); err != nil {
+		return false, err
 	}
-	return
+	return true, nil
 }
 
+// DeleteCookie expires cookie and its chunks with same attributes it was written with.
+// With TicketStore set, ticket of cookie is removed as well.
 func DeleteCookie(r *http.Request, w http.ResponseWriter, cfg *Config) error {
-	cookie, err := r.Cookie(cfg.cookieName)
+	cookie, err := r.Cookie(cfg.CookieName())
 	if err != nil {
 		return err
 	}
-	cookie.MaxAge = -1
+	if cfg.ticketStore != nil && isSessionID(cookie.Value) {
+		if err = cfg.ticketStore.Remove(r.Context(), cookie.Value); err != nil {
+			return err
+		}
+	}
+	chunks, _ := chunkCount(cookie.Value)
+	for i := range chunks + 1 {
+		name := cookie.Name
+		if i > 0 {
+			name = chunkName(cookie.Name, i)
+		}
+		c := cfg.NewCookie(name, "")
+		c.MaxAge = -1
+		http.SetCookie(w, c)
+	}
+	return nil
+}
+
+// NewCookie creates cookie with attributes of config, attributes required by cookie prefix take precedence.
+// Companion cookies of sign in flows use it to follow WithSecure, WithCookiePath and WithCookieDomain.
+func (c *Config) NewCookie(name, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     c.cookiePath,
+		Domain:   c.cookieDomain,
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: c.sameSite,
+	}
+	switch c.cookiePrefix {
+	case CookiePrefixHost:
+		cookie.Path, cookie.Domain, cookie.Secure = "/", "", true
+	case CookiePrefixSecure:
+		cookie.Secure = true
+	}
+	return cookie
+}
+
+// setCookie writes cookie holding value, zero expires makes it session cookie. Value not fitting single cookie
+// is split into chunks `<name>C1`...`<name>CN` and cookie itself holds number of chunks.
+func (c *Config) setCookie(w http.ResponseWriter, value string, expires time.Time) error {
+	name := c.CookieName()
+	cookie := c.NewCookie(name, value)
+	cookie.Expires = expires
+	if len(cookie.String()) <= maxCookieSize {
+		http.SetCookie(w, cookie)
+		return nil
+	}
+
+	empty := c.NewCookie(chunkName(name, maxCookieChunks), "")
+	empty.Expires = expires
+	size := maxCookieSize - len(empty.String())
+	chunks := (len(value) + size - 1) / size
+	if chunks > maxCookieChunks {
+		return ErrorAuthCookieTooLarge
+	}
+	cookie.Value = chunksPrefix + strconv.Itoa(chunks)
 	http.SetCookie(w, cookie)
+	for i := range chunks {
+		chunk := c.NewCookie(chunkName(name, i+1), value[i*size:min((i+1)*size, len(value))])
+		chunk.Expires = expires
+		http.SetCookie(w, chunk)
+	}
 	return nil
 }
+
+// readCookie returns value of cookie, joining its chunks
+func (c *Config) readCookie(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(c.CookieName())
+	if err != nil {
+		return "", ErrorAuthCookieMissing
+	}
+	if err = cookie.Valid(); err != nil {
+		return "", err
+	}
+	chunks, ok := chunkCount(cookie.Value)
+	if !ok {
+		return cookie.Value, nil
+	}
+	var b strings.Builder
+	for i := range chunks {
+		chunk, err := r.Cookie(chunkName(cookie.Name, i+1))
+		if err != nil {
+			return "", ErrorAuthCookieMissing
+		}
+		b.WriteString(chunk.Value)
+	}
+	return b.String(), nil
+}
+
+func chunkName(name string, i int) string {
+	return name + "C" + strconv.Itoa(i)
+}
+
+// chunkCount returns number of chunks when cookie value refers to chunks
+func chunkCount(value string) (int, bool) {
+	n, ok := strings.CutPrefix(value, chunksPrefix)
+	if !ok {
+		return 0, false
+	}
+	chunks, err := strconv.Atoi(n)
+	if err != nil || chunks < 1 || chunks > maxCookieChunks {
+		return 0, false
+	}
+	return chunks, true
+}
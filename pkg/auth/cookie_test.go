@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestConfig(t *testing.T, opts ...OptFn) (*Config, *testClock) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	opts = append([]OptFn{WithSigningKey(getEcdsaKey(t)), WithExpiration(time.Hour), WithClock(clock.Now)}, opts...)
+	return NewConfig(opts...), clock
+}
+
+func issueCookie(t *testing.T, cfg *Config) *http.Cookie {
+	t.Helper()
+	w := httptest.NewRecorder()
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	if err := cv.WriteToRequest(w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return w.Result().Cookies()[0]
+}
+
+func readCookie(t *testing.T, cfg *Config, cookie *http.Cookie) (*CookieValue, error) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	return GetCookie(r, cfg)
+}
+
+func TestGetCookieExpiration(t *testing.T) {
+	cfg, clock := newTestConfig(t)
+	cookie := issueCookie(t, cfg)
+
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IssuedAt.Equal(clock.now) || !cv.Timestamp.Equal(clock.now.Add(time.Hour)) {
+		t.Errorf("unexpected lifetime %s - %s", cv.IssuedAt, cv.Timestamp)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err = readCookie(t, cfg, cookie); !errors.Is(err, ErrorAuthCookieExpired) {
+		t.Errorf("expected expired cookie, got %v", err)
+	}
+}
+
+func TestRenewCookie(t *testing.T) {
+	cfg, clock := newTestConfig(t, IsSliding(), WithRenewThreshold(20*time.Minute), WithMaxLifetime(2*time.Hour))
+	cookie := issueCookie(t, cfg)
+	issuedAt := clock.now
+
+	renew := func() (*http.Cookie, bool) {
+		t.Helper()
+		cv, err := readCookie(t, cfg, cookie)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		renewed, err := RenewCookie(context.Background(), w, cv, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if renewed {
+			return w.Result().Cookies()[0], true
+		}
+		return cookie, false
+	}
+
+	clock.Advance(30 * time.Minute)
+	if _, renewed := renew(); renewed {
+		t.Error("cookie renewed above threshold")
+	}
+
+	clock.Advance(15 * time.Minute)
+	cookie, renewed := renew()
+	if !renewed {
+		t.Fatal("cookie not renewed below threshold")
+	}
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cv.IssuedAt.Equal(issuedAt) || !cv.Timestamp.Equal(clock.now.Add(time.Hour)) {
+		t.Errorf("unexpected lifetime after renewal %s - %s", cv.IssuedAt, cv.Timestamp)
+	}
+
+	clock.Advance(45 * time.Minute)
+	cookie, renewed = renew()
+	if !renewed {
+		t.Fatal("cookie not renewed below threshold")
+	}
+	if cv, _ = readCookie(t, cfg, cookie); !cv.Timestamp.Equal(issuedAt.Add(2 * time.Hour)) {
+		t.Errorf("renewal not capped by max lifetime, expires %s", cv.Timestamp)
+	}
+
+	clock.Advance(15 * time.Minute)
+	if _, renewed = renew(); renewed {
+		t.Error("cookie renewed past max lifetime")
+	}
+	clock.Advance(15 * time.Minute)
+	if _, err = readCookie(t, cfg, cookie); !errors.Is(err, ErrorAuthCookieExpired) {
+		t.Errorf("expected expired cookie, got %v", err)
+	}
+}
+
+func TestRenewCookieNotSliding(t *testing.T) {
+	cfg, clock := newTestConfig(t)
+	cookie := issueCookie(t, cfg)
+	clock.Advance(55 * time.Minute)
+
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed, _ := RenewCookie(context.Background(), httptest.NewRecorder(), cv, cfg); renewed {
+		t.Error("non sliding cookie renewed")
+	}
+}
+
+func TestCookieAttributes(t *testing.T) {
+	cfg, _ := newTestConfig(t, WithCookieDomain("example.com"), WithCookiePath("/app"), WithSameSite(http.SameSiteLaxMode),
+		WithSecure(false))
+	cookie := issueCookie(t, cfg)
+	if cookie.Domain != "example.com" || cookie.Path != "/app" || cookie.SameSite != http.SameSiteLaxMode || cookie.Secure {
+		t.Errorf("unexpected attributes %s", cookie)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	if err := DeleteCookie(r, w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	deleted := w.Result().Cookies()[0]
+	if deleted.MaxAge != -1 || deleted.Domain != "example.com" || deleted.Path != "/app" || deleted.SameSite != http.SameSiteLaxMode {
+		t.Errorf("deleted cookie lost attributes %s", deleted)
+	}
+}
+
+func TestCookiePrefix(t *testing.T) {
+	cfg, _ := newTestConfig(t, WithCookiePrefix(CookiePrefixHost), WithCookieDomain("example.com"), WithSecure(false))
+	cookie := issueCookie(t, cfg)
+	if cookie.Name != "__Host-auth" || cookie.Domain != "" || cookie.Path != "/" || !cookie.Secure {
+		t.Errorf("cookie doesn't satisfy prefix %s", cookie)
+	}
+	if _, err := readCookie(t, cfg, cookie); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSessionOnlyCookie(t *testing.T) {
+	cfg, clock := newTestConfig(t, IsSliding())
+	w := httptest.NewRecorder()
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.SessionOnly = true
+	if err := cv.WriteToRequest(w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cookie := w.Result().Cookies()[0]
+	if !cookie.Expires.IsZero() {
+		t.Errorf("session cookie has expiration %s", cookie.Expires)
+	}
+
+	clock.Advance(45 * time.Minute)
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	if renewed, err := RenewCookie(context.Background(), w, cv, cfg); err != nil || !renewed {
+		t.Fatalf("cookie not renewed: %v", err)
+	}
+	if renewed := w.Result().Cookies()[0]; !renewed.Expires.IsZero() {
+		t.Error("renewal made session cookie persistent")
+	}
+}
+
+func TestCookieChunks(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	w := httptest.NewRecorder()
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Claims.SetClaim("large", strings.Repeat("x", 2*maxCookieSize))
+	if err := cv.WriteToRequest(w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 4 || cookies[0].Value != "chunks-3" || cookies[3].Name != "authC3" {
+		t.Fatalf("unexpected chunks %d", len(cookies))
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range cookies {
+		if len(c.String()) > maxCookieSize {
+			t.Errorf("chunk %s exceeds maximum size", c.Name)
+		}
+		r.AddCookie(c)
+	}
+	got, err := GetCookie(r, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := got.Claims.Claim("large"); len(v) != 2*maxCookieSize {
+		t.Error("claim lost in chunks")
+	}
+
+	w = httptest.NewRecorder()
+	if err = DeleteCookie(r, w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if deleted := w.Result().Cookies(); len(deleted) != 4 || deleted[3].MaxAge != -1 {
+		t.Errorf("chunks not deleted, got %d cookies", len(deleted))
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	if _, err = GetCookie(r, cfg); !errors.Is(err, ErrorAuthCookieMissing) {
+		t.Errorf("expected missing chunk error, got %v", err)
+	}
+}
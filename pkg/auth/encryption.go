@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+type (
+	EncryptionAlgorithm uint8
+
+	// EncryptionKey is symmetric key auth tokens are encrypted with
+	EncryptionKey struct {
+		id   string
+		aead cipher.AEAD
+	}
+	// EncryptionKeyring holds active encryption key and keys tokens are still decrypted with, i.e. keys rotated out
+	EncryptionKeyring struct {
+		active *EncryptionKey
+		keys   []*EncryptionKey
+	}
+)
+
+const (
+	// AESGCM uses AES-GCM with 16, 24 or 32 byte key
+	AESGCM EncryptionAlgorithm = iota + 1
+	// XChaCha20Poly1305 uses XChaCha20-Poly1305 with 32 byte key
+	XChaCha20Poly1305
+)
+
+// tokenEncryptedV1 is first byte of encrypted token, its plaintext is binary token
+const tokenEncryptedV1 byte = 2
+
+var (
+	ErrTokenNotDecrypted = errors.New("auth: Unable to decrypt auth token")
+	ErrNoEncryptionKey   = errors.New("auth: No active encryption key")
+)
+
+// NewEncryptionKey creates key of algorithm from secret. Key ID is derived from secret and algorithm,
+// so same secret always has same ID.
+func NewEncryptionKey(alg EncryptionAlgorithm, secret []byte) (*EncryptionKey, error) {
+	var (
+		aead cipher.AEAD
+		err  error
+	)
+	switch alg {
+	case AESGCM:
+		var block cipher.Block
+		if block, err = aes.NewCipher(secret); err == nil {
+			aead, err = cipher.NewGCM(block)
+		}
+	case XChaCha20Poly1305:
+		aead, err = chacha20poly1305.NewX(secret)
+	default:
+		return nil, fmt.Errorf("%w: encryption algorithm %d", ErrUnsupportedKey, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	h.Write([]byte("auth/encryption-key"))
+	h.Write([]byte{byte(alg)})
+	h.Write(secret)
+	return &EncryptionKey{id: base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:8]), aead: aead}, nil
+}
+
+// NewEncryptionSecret generates random secret of length suitable for both algorithms
+func NewEncryptionSecret() ([]byte, error) {
+	secret := make([]byte, chacha20poly1305.KeySize)
+	_, err := rand.Read(secret)
+	return secret, err
+}
+
+// NewEncryptionKeyring creates keyring encrypting with active key and decrypting with active and every other key
+func NewEncryptionKeyring(active *EncryptionKey, decrypt ...*EncryptionKey) (*EncryptionKeyring, error) {
+	if active == nil {
+		return nil, ErrNoEncryptionKey
+	}
+	kr := &EncryptionKeyring{active: active, keys: []*EncryptionKey{active}}
+	for i, k := range decrypt {
+		if k == nil {
+			return nil, fmt.Errorf("%w: decryption key %d is nil", ErrUnsupportedKey, i)
+		}
+		if k.id != active.id {
+			kr.keys = append(kr.keys, k)
+		}
+	}
+	return kr, nil
+}
+
+func (k *EncryptionKey) ID() string {
+	return k.id
+}
+
+// Active returns key new tokens are encrypted with
+func (kr *EncryptionKeyring) Active() *EncryptionKey {
+	return kr.active
+}
+
+// Key returns key by its ID
+func (kr *EncryptionKeyring) Key(id string) (*EncryptionKey, bool) {
+	for _, k := range kr.keys {
+		if k.id == id {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns all keys of keyring, active key first
+func (kr *EncryptionKeyring) Keys() []*EncryptionKey {
+	return kr.keys
+}
+
+// seal encrypts token with active key. Encrypted token layout is
+//
+//	version | kid | nonce | ciphertext
+//
+// where version and kid are authenticated as additional data
+func (kr *EncryptionKeyring) seal(token []byte) ([]byte, error) {
+	k := kr.active
+	header := appendTokenBytes([]byte{tokenEncryptedV1}, []byte(k.id))
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(token)+k.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return append(header, k.aead.Seal(nonce, nonce, token, header)...), nil
+}
+
+// open decrypts token sealed by seal with key of its key ID
+func (kr *EncryptionKeyring) open(data []byte) ([]byte, error) {
+	r := &tokenReader{data: data[1:]}
+	keyID := string(r.bytes())
+	if r.err != nil {
+		return nil, ErrMalformedToken
+	}
+	k, ok := kr.Key(keyID)
+	if !ok {
+		return nil, ErrTokenNotDecrypted
+	}
+	nonce := r.fixed(k.aead.NonceSize())
+	if r.err != nil {
+		return nil, ErrMalformedToken
+	}
+	header := data[:len(data)-len(r.data)-len(nonce)]
+	token, err := k.aead.Open(nil, nonce, r.data, header)
+	if err != nil {
+		return nil, ErrTokenNotDecrypted
+	}
+	return token, nil
+}
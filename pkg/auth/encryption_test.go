@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func newTestEncryptionKey(t *testing.T, alg EncryptionAlgorithm) *EncryptionKey {
+	t.Helper()
+	secret, err := NewEncryptionSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewEncryptionKey(alg, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newTestEncryptionKeyring(t *testing.T, active *EncryptionKey, decrypt ...*EncryptionKey) *EncryptionKeyring {
+	t.Helper()
+	kr, err := NewEncryptionKeyring(active, decrypt...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	for _, alg := range []EncryptionAlgorithm{AESGCM, XChaCha20Poly1305} {
+		cfg, _ := newTestConfig(t, WithEncryption(newTestEncryptionKeyring(t, newTestEncryptionKey(t, alg))))
+		cookie := issueCookie(t, cfg)
+
+		data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data[0] != tokenEncryptedV1 || bytes.Contains(data, []byte("username")) || bytes.Contains(data, []byte("0123456789abcdef")) {
+			t.Errorf("algorithm %d: cookie content is readable", alg)
+		}
+
+		cv, err := readCookie(t, cfg, cookie)
+		if err != nil {
+			t.Fatalf("algorithm %d: %v", alg, err)
+		}
+		if cv.Username != "username" {
+			t.Errorf("algorithm %d: unexpected username %s", alg, cv.Username)
+		}
+
+		data[len(data)-1] ^= 0x01
+		cookie.Value = base64.RawURLEncoding.EncodeToString(data)
+		if _, err = readCookie(t, cfg, cookie); !errors.Is(err, ErrTokenNotDecrypted) {
+			t.Errorf("algorithm %d: expected tampered cookie to fail decryption, got %v", alg, err)
+		}
+	}
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	oldKey, newKey := newTestEncryptionKey(t, AESGCM), newTestEncryptionKey(t, XChaCha20Poly1305)
+	plainCfg, _ := newTestConfig(t)
+	oldCfg, _ := newTestConfig(t, WithEncryption(newTestEncryptionKeyring(t, oldKey)))
+	newCfg, _ := newTestConfig(t, WithEncryption(newTestEncryptionKeyring(t, newKey, oldKey)))
+
+	if _, err := readCookie(t, newCfg, issueCookie(t, oldCfg)); err != nil {
+		t.Errorf("cookie encrypted by previous key not accepted: %v", err)
+	}
+	if _, err := readCookie(t, newCfg, issueCookie(t, plainCfg)); err != nil {
+		t.Errorf("unencrypted cookie not accepted: %v", err)
+	}
+
+	cookie := issueCookie(t, newCfg)
+	if _, err := readCookie(t, oldCfg, cookie); !errors.Is(err, ErrTokenNotDecrypted) {
+		t.Errorf("expected cookie of unknown key to fail decryption, got %v", err)
+	}
+	if _, err := readCookie(t, plainCfg, cookie); !errors.Is(err, ErrTokenNotDecrypted) {
+		t.Errorf("expected encrypted cookie to fail without encryption, got %v", err)
+	}
+}
+
+func TestNewEncryptionKey(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+	a, _ := NewEncryptionKey(AESGCM, secret)
+	b, _ := NewEncryptionKey(AESGCM, secret)
+	c, _ := NewEncryptionKey(XChaCha20Poly1305, secret)
+	if a.ID() != b.ID() || a.ID() == c.ID() {
+		t.Error("key ID should depend on secret and algorithm only")
+	}
+
+	if _, err := NewEncryptionKey(XChaCha20Poly1305, secret[:16]); err == nil {
+		t.Error("expected error for short XChaCha20-Poly1305 secret")
+	}
+	if _, err := NewEncryptionKey(0, secret); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("expected ErrUnsupportedKey, got %v", err)
+	}
+	if _, err := NewEncryptionKeyring(nil); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Errorf("expected ErrNoEncryptionKey, got %v", err)
+	}
+	key, err := NewEncryptionKey(XChaCha20Poly1305, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewEncryptionKeyring(key, nil); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("expected ErrUnsupportedKey for nil decryption key, got %v", err)
+	}
+}
@@ -9,8 +9,12 @@ type (
 	Identity interface {
 		ID() uuid.UUID
 		Username() string
+		HasRole(role string) bool
+		Claim(name string) (string, bool)
+		Claims() ClaimSet
 	}
 	identity struct {
+		ClaimSet
 		id       uuid.UUID
 		username string
 	}
@@ -21,7 +25,7 @@ var _ Identity = (*identity)(nil)
 const identityContextKey key = "auth/identity"
 
 func NewIdentity(cv *CookieValue) (Identity, error) {
-	return &identity{id: cv.ID, username: cv.Username}, nil
+	return &identity{ClaimSet: cv.Claims.Clone(), id: cv.ID, username: cv.Username}, nil
 }
 
 func (i *identity) ID() uuid.UUID {
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+type (
+	// Key is signing key, or verification-only key when it has only public part
+	Key struct {
+		id     string
+		signer crypto.Signer
+		public crypto.PublicKey
+	}
+	// Keyring holds active signing key and keys tokens are still verified with, i.e. keys rotated out
+	Keyring struct {
+		active *Key
+		keys   []*Key
+	}
+)
+
+var (
+	ErrUnsupportedKey = errors.New("auth: Unsupported key type")
+	ErrNoSigningKey   = errors.New("auth: Active key has no private part")
+)
+
+// NewKey wraps *ecdsa.PrivateKey, ed25519.PrivateKey or their public keys. Key ID is derived from public key,
+// so same key always has same ID. Returns ErrNoSigningKey for nil private key.
+func NewKey(key any) (*Key, error) {
+	k := &Key{}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		if key == nil {
+			return nil, ErrNoSigningKey
+		}
+		k.signer, k.public = key, &key.PublicKey
+	case ed25519.PrivateKey:
+		if len(key) == 0 {
+			return nil, ErrNoSigningKey
+		}
+		k.signer, k.public = key, key.Public()
+	case *ed25519.PrivateKey:
+		if key == nil || len(*key) == 0 {
+			return nil, ErrNoSigningKey
+		}
+		k.signer, k.public = *key, key.Public()
+	case *ecdsa.PublicKey:
+		if key == nil {
+			return nil, ErrUnsupportedKey
+		}
+		k.public = key
+	case ed25519.PublicKey:
+		k.public = key
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(k.public)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	k.id = base64.RawURLEncoding.EncodeToString(sum[:8])
+	return k, nil
+}
+
+// NewKeyring creates keyring signing with active key and verifying with active and every other key
+func NewKeyring(active *Key, verify ...*Key) (*Keyring, error) {
+	if active == nil || active.signer == nil {
+		return nil, ErrNoSigningKey
+	}
+	kr := &Keyring{active: active, keys: []*Key{active}}
+	for i, k := range verify {
+		if k == nil {
+			return nil, fmt.Errorf("%w: verification key %d is nil", ErrUnsupportedKey, i)
+		}
+		if k.id != active.id {
+			kr.keys = append(kr.keys, k)
+		}
+	}
+	return kr, nil
+}
+
+func (k *Key) ID() string {
+	return k.id
+}
+
+func (k *Key) Public() crypto.PublicKey {
+	return k.public
+}
+
+// CanSign reports whether key has private part
+func (k *Key) CanSign() bool {
+	return k.signer != nil
+}
+
+func (k *Key) sign(digest []byte) ([]byte, error) {
+	switch key := k.signer.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, key, digest)
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, digest), nil
+	}
+	return nil, ErrNoSigningKey
+}
+
+func (k *Key) verify(digest, signature []byte) bool {
+	switch pub := k.public.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(pub, digest, signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, digest, signature)
+	}
+	return false
+}
+
+// Active returns key new tokens are signed with
+func (kr *Keyring) Active() *Key {
+	return kr.active
+}
+
+// Key returns key by its ID
+func (kr *Keyring) Key(id string) (*Key, bool) {
+	for _, k := range kr.keys {
+		if k.id == id {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns all keys of keyring, active key first
+func (kr *Keyring) Keys() []*Key {
+	return kr.keys
+}
+
+// verify checks signature with key of keyID. Tokens issued without key ID are checked against every key
+func (kr *Keyring) verify(keyID string, digest, signature []byte) bool {
+	if keyID != "" {
+		k, ok := kr.Key(keyID)
+		return ok && k.verify(digest, signature)
+	}
+	for _, k := range kr.keys {
+		if k.verify(digest, signature) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func newTestKey(t *testing.T, ed bool) *Key {
+	t.Helper()
+	var (
+		key any
+		err error
+	)
+	if ed {
+		key, err = NewSigningKeyEd25519()
+	} else {
+		key, err = NewSigningKey()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newTestKeyring(t *testing.T, active *Key, verify ...*Key) *Keyring {
+	t.Helper()
+	kr, err := NewKeyring(active, verify...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey, newKey := newTestKey(t, false), newTestKey(t, true)
+	oldCfg, _ := newTestConfig(t, WithKeyring(newTestKeyring(t, oldKey)))
+	newCfg, _ := newTestConfig(t, WithKeyring(newTestKeyring(t, newKey, oldKey)))
+
+	cv, err := readCookie(t, newCfg, issueCookie(t, oldCfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.KeyID != oldKey.ID() {
+		t.Errorf("expected key ID %s, got %s", oldKey.ID(), cv.KeyID)
+	}
+
+	cookie := issueCookie(t, newCfg)
+	if cv, err = readCookie(t, newCfg, cookie); err != nil {
+		t.Fatal(err)
+	}
+	if cv.KeyID != newKey.ID() {
+		t.Errorf("expected key ID %s, got %s", newKey.ID(), cv.KeyID)
+	}
+	if _, err = readCookie(t, oldCfg, cookie); !errors.Is(err, ErrKeyNotVerified) {
+		t.Errorf("expected token of unknown key to fail verification, got %v", err)
+	}
+}
+
+func TestKeyringVerificationOnly(t *testing.T) {
+	k := newTestKey(t, false)
+	public, err := NewKey(k.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if public.ID() != k.ID() || public.CanSign() {
+		t.Error("public key should have same ID and no private part")
+	}
+	if _, err = NewKeyring(public); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("expected ErrNoSigningKey, got %v", err)
+	}
+	if _, err = NewKey("key"); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("expected ErrUnsupportedKey, got %v", err)
+	}
+	if _, err = NewKeyring(newTestKey(t, false), public, nil); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("expected ErrUnsupportedKey for nil verification key, got %v", err)
+	}
+}
+
+func TestNilSigningKey(t *testing.T) {
+	if _, err := NewKey((*ecdsa.PrivateKey)(nil)); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("expected ErrNoSigningKey, got %v", err)
+	}
+
+	cfg := NewConfig(WithSigningKey(nil))
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "user", []byte("stamp"))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := IssueCookie(httptest.NewRecorder(), r, cv, cfg); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("expected ErrNoSigningKey, got %v", err)
+	}
+
+	// keyring set by later option replaces failed one
+	kr, err := NewKeyring(newTestKey(t, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = NewConfig(WithSigningKey(nil), WithKeyring(kr))
+	if err = IssueCookie(httptest.NewRecorder(), r, cv, cfg); err != nil {
+		t.Errorf("expected keyring of later option to sign, got %v", err)
+	}
+}
+
+func TestLoadKeyringDir(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadKeyringDir(dir); !errors.Is(err, ErrNoKeysFound) {
+		t.Errorf("expected ErrNoKeysFound, got %v", err)
+	}
+
+	first, _, err := GenerateKeyFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, secondFile, err := GenerateKeyFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := NewSigningKeyEd25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// public keys never become active, regardless of name
+	err = os.WriteFile(filepath.Join(dir, "zz-public.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kr, err := LoadKeyringDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kr.Active().ID() != second.ID() {
+		t.Errorf("expected newest key %s to be active, got %s", second.ID(), kr.Active().ID())
+	}
+	if len(kr.Keys()) != 3 {
+		t.Errorf("expected 3 keys, got %d", len(kr.Keys()))
+	}
+	if _, ok := kr.Key(first.ID()); !ok {
+		t.Error("previous key missing from keyring")
+	}
+
+	kr, err = LoadKeyringFiles(secondFile, filepath.Join(dir, "zz-public.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kr.Keys()) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(kr.Keys()))
+	}
+
+	if k, err := ParseKeyPEM([]byte(certStr)); err != nil || !k.CanSign() {
+		t.Errorf("failed to parse EC PRIVATE KEY: %v", err)
+	}
+}
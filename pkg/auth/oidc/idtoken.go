@@ -0,0 +1,159 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// Claims are claims of verified ID token
+	Claims struct {
+		Issuer            string   `json:"iss"`
+		Subject           string   `json:"sub"`
+		Audience          Audience `json:"aud"`
+		AuthorizedParty   string   `json:"azp,omitempty"`
+		ExpiresAt         int64    `json:"exp"`
+		IssuedAt          int64    `json:"iat"`
+		Nonce             string   `json:"nonce,omitempty"`
+		AuthMethods       []string `json:"amr,omitempty"`
+		Email             string   `json:"email,omitempty"`
+		EmailVerified     bool     `json:"email_verified,omitempty"`
+		Name              string   `json:"name,omitempty"`
+		PreferredUsername string   `json:"preferred_username,omitempty"`
+		// Raw holds all claims of token, i.e. provider specific ones
+		Raw map[string]any `json:"-"`
+	}
+
+	// Audience is `aud` claim, which is single string or array of strings
+	Audience []string
+)
+
+// Supported ID token signing algorithms
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+)
+
+// VerifyIDToken verifies signature of ID token against provider JWKS and validates its issuer, audience,
+// expiration and nonce
+func (rp *RelyingParty) VerifyIDToken(ctx context.Context, token, nonce string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidIDToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	// algorithm is checked before key lookup, so that `none` and HMAC tokens are never accepted
+	if header.Alg != algRS256 && header.Alg != algES256 {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidIDToken, header.Alg)
+	}
+	if algs := rp.provider.IDTokenSigningAlgValuesSupported; len(algs) > 0 && !slices.Contains(algs, header.Alg) {
+		return nil, fmt.Errorf("%w: algorithm %q not supported by provider", ErrInvalidIDToken, header.Alg)
+	}
+	key, err := rp.keys.key(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !verifySignature(key, header.Alg, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidIDToken)
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = decodeSegment(parts[1], &claims.Raw); err != nil {
+		return nil, err
+	}
+	if err = rp.validateClaims(&claims, nonce); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// validateClaims validates claims of OpenID Connect Core 1.0 section 3.1.3.7
+func (rp *RelyingParty) validateClaims(claims *Claims, nonce string) error {
+	if claims.Issuer != rp.provider.Issuer {
+		return fmt.Errorf("%w: issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	if !slices.Contains(claims.Audience, rp.clientID) {
+		return fmt.Errorf("%w: audience %q", ErrInvalidIDToken, claims.Audience)
+	}
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != rp.clientID {
+		return fmt.Errorf("%w: authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	now := rp.clock()
+	if claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0).Add(rp.leeway)) {
+		return ErrIDTokenExpired
+	}
+	if time.Unix(claims.IssuedAt, 0).After(now.Add(rp.leeway)) {
+		return fmt.Errorf("%w: issued in future", ErrInvalidIDToken)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return ErrNonceMismatch
+	}
+	return nil
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(data, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	return nil
+}
+
+// verifySignature verifies JWS signature of data, ES256 signatures are raw concatenation of R and S
+func verifySignature(key crypto.PublicKey, alg string, data, signature []byte) bool {
+	sum := sha256.Sum256(data)
+	switch alg {
+	case algRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], signature) == nil
+	case algES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, sum[:], r, s)
+	}
+	return false
+}
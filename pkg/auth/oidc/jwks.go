@@ -0,0 +1,149 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// keySet caches signing keys of provider JWKS, unknown key IDs refresh it to follow key rotation
+	keySet struct {
+		mu          sync.Mutex
+		client      *http.Client
+		uri         string
+		clock       func() time.Time
+		keys        []jsonWebKey
+		refreshedAt time.Time
+	}
+
+	jsonWebKey struct {
+		id  string
+		alg string
+		key crypto.PublicKey
+	}
+
+	rawJSONWebKey struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+// minRefreshInterval limits refreshes of key set by tokens of unknown key IDs
+const minRefreshInterval = time.Minute
+
+// minRSAKeySize is minimal accepted size of RSA keys in bits
+const minRSAKeySize = 2048
+
+func newKeySet(client *http.Client, uri string, clock func() time.Time) *keySet {
+	return &keySet{client: client, uri: uri, clock: clock}
+}
+
+// key returns key of ID for algorithm, key set is refreshed when it doesn't have such key
+func (ks *keySet) key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if key := ks.find(kid, alg); key != nil {
+		return key, nil
+	}
+	if !ks.refreshedAt.IsZero() && ks.clock().Sub(ks.refreshedAt) < minRefreshInterval {
+		return nil, ErrKeyNotFound
+	}
+	if err := ks.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key := ks.find(kid, alg); key != nil {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// find returns key of ID, token without key ID matches single key of algorithm
+func (ks *keySet) find(kid, alg string) crypto.PublicKey {
+	var found crypto.PublicKey
+	for _, k := range ks.keys {
+		if k.alg != alg || (kid != "" && k.id != kid) {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = k.key
+	}
+	return found
+}
+
+func (ks *keySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.uri, nil)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []rawJSONWebKey `json:"keys"`
+	}
+	if err = getJSON(ks.client, req, &set); err != nil {
+		return errors.Join(ErrKeyNotFound, err)
+	}
+	ks.refreshedAt = ks.clock()
+	ks.keys = ks.keys[:0]
+	for _, raw := range set.Keys {
+		// unsupported keys are skipped, set may carry keys of other algorithms or for encryption
+		if k, ok := raw.parse(); ok {
+			ks.keys = append(ks.keys, k)
+		}
+	}
+	return nil
+}
+
+func (raw rawJSONWebKey) parse() (jsonWebKey, bool) {
+	if raw.Use != "" && raw.Use != "sig" {
+		return jsonWebKey{}, false
+	}
+	switch {
+	case raw.Kty == "RSA" && (raw.Alg == "" || raw.Alg == algRS256):
+		n, err := base64.RawURLEncoding.DecodeString(raw.N)
+		if err != nil {
+			return jsonWebKey{}, false
+		}
+		e, err := base64.RawURLEncoding.DecodeString(raw.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return jsonWebKey{}, false
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < minRSAKeySize || key.E < 3 {
+			return jsonWebKey{}, false
+		}
+		return jsonWebKey{id: raw.Kid, alg: algRS256, key: key}, true
+	case raw.Kty == "EC" && raw.Crv == "P-256" && (raw.Alg == "" || raw.Alg == algES256):
+		x, err := base64.RawURLEncoding.DecodeString(raw.X)
+		if err != nil || len(x) != 32 {
+			return jsonWebKey{}, false
+		}
+		y, err := base64.RawURLEncoding.DecodeString(raw.Y)
+		if err != nil || len(y) != 32 {
+			return jsonWebKey{}, false
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return jsonWebKey{}, false
+		}
+		return jsonWebKey{id: raw.Kid, alg: algES256, key: key}, true
+	}
+	return jsonWebKey{}, false
+}
@@ -0,0 +1,395 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pudottapommin/golib/pkg/auth"
+)
+
+type (
+	OptFn func(*RelyingParty)
+	// RelyingParty signs users in with OpenID Connect provider by authorization code flow with PKCE
+	RelyingParty struct {
+		clientID     string
+		clientSecret string
+		redirectURL  string
+		scopes       []string
+		provider     *ProviderMetadata
+		keys         *keySet
+		client       *http.Client
+		mapper       ClaimsMapper
+		authConfig   *auth.Config
+		cookieName   string
+		timeout      time.Duration
+		leeway       time.Duration
+		clock        func() time.Time
+	}
+
+	// ProviderMetadata is provider configuration of OpenID Connect Discovery 1.0
+	ProviderMetadata struct {
+		Issuer                           string   `json:"issuer"`
+		AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+		TokenEndpoint                    string   `json:"token_endpoint"`
+		UserinfoEndpoint                 string   `json:"userinfo_endpoint,omitempty"`
+		JWKSURI                          string   `json:"jwks_uri"`
+		ScopesSupported                  []string `json:"scopes_supported,omitempty"`
+		IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+		CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported,omitempty"`
+	}
+
+	// Token is response of token endpoint
+	Token struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		ExpiresIn    int64  `json:"expires_in,omitempty"`
+		IDToken      string `json:"id_token"`
+	}
+
+	// ClaimsMapper maps verified ID token claims to cookie value of user, i.e. by looking up or provisioning
+	// user of issuer and subject
+	ClaimsMapper func(ctx context.Context, claims *Claims) (*auth.CookieValue, error)
+)
+
+// AuthMethodOIDC is auth method SignIn sets when claims mapper doesn't set one
+const AuthMethodOIDC = "oidc"
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// randomSize is size of random state, nonce and PKCE code verifier
+	randomSize = 32
+	// maxResponseSize limits size of provider responses
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrDiscovery           = errors.New("oidc: Provider discovery failed")
+	ErrStateMismatch       = errors.New("oidc: State doesn't match")
+	ErrAuthorizationFailed = errors.New("oidc: Authorization failed")
+	ErrTokenExchange       = errors.New("oidc: Token exchange failed")
+	ErrInvalidIDToken      = errors.New("oidc: Invalid ID token")
+	ErrIDTokenExpired      = errors.New("oidc: ID token has expired")
+	ErrNonceMismatch       = errors.New("oidc: Nonce doesn't match")
+	ErrKeyNotFound         = errors.New("oidc: Signing key not found")
+	ErrNoClaimsMapper      = errors.New("oidc: No claims mapper configured")
+	ErrNoCookieValue       = errors.New("oidc: Claims mapper returned no cookie value")
+	ErrNoAuthConfig        = errors.New("oidc: Auth config is required")
+)
+
+// New creates relying party of client registered at issuer, provider configuration is discovered from
+// `<issuer>/.well-known/openid-configuration`. Client secret is empty for public clients. WithAuthConfig
+// is required, returns ErrNoAuthConfig without it.
+func New(ctx context.Context, issuer, clientID, clientSecret, redirectURL string, opts ...OptFn) (*RelyingParty, error) {
+	rp := &RelyingParty{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       []string{"openid", "profile", "email"},
+		provider:     nil,
+		keys:         nil,
+		client:       &http.Client{Timeout: time.Second * 10},
+		mapper:       nil,
+		authConfig:   nil,
+		cookieName:   "oidc",
+		timeout:      time.Minute * 10,
+		leeway:       time.Minute,
+		clock:        time.Now,
+	}
+	for _, o := range opts {
+		o(rp)
+	}
+	if rp.authConfig == nil {
+		return nil, ErrNoAuthConfig
+	}
+
+	provider, err := Discover(ctx, rp.client, issuer)
+	if err != nil {
+		return nil, err
+	}
+	rp.provider = provider
+	rp.keys = newKeySet(rp.client, provider.JWKSURI, rp.clock)
+	return rp, nil
+}
+
+// WithScopes sets requested scopes, `openid` is always requested. Defaults to `openid profile email`
+func WithScopes(scopes ...string) OptFn {
+	return func(rp *RelyingParty) {
+		if !slices.Contains(scopes, "openid") {
+			scopes = append([]string{"openid"}, scopes...)
+		}
+		rp.scopes = scopes
+	}
+}
+
+// WithHTTPClient sets client of requests to provider. Defaults to client with 10 seconds timeout
+func WithHTTPClient(client *http.Client) OptFn {
+	return func(rp *RelyingParty) {
+		rp.client = client
+	}
+}
+
+// WithClaimsMapper sets mapping of ID token claims to cookie value, required by SignIn
+func WithClaimsMapper(mapper ClaimsMapper) OptFn {
+	return func(rp *RelyingParty) {
+		rp.mapper = mapper
+	}
+}
+
+// WithAuthConfig sets config of auth cookie SignIn issues
+func WithAuthConfig(cfg *auth.Config) OptFn {
+	return func(rp *RelyingParty) {
+		rp.authConfig = cfg
+	}
+}
+
+// WithCookieName sets prefix of state and nonce cookie names. Defaults to `oidc`
+func WithCookieName(name string) OptFn {
+	return func(rp *RelyingParty) {
+		rp.cookieName = name
+	}
+}
+
+// WithTimeout sets how long user has to complete sign in at provider. Defaults to 10 minutes
+func WithTimeout(d time.Duration) OptFn {
+	return func(rp *RelyingParty) {
+		rp.timeout = d
+	}
+}
+
+// WithLeeway sets allowed clock skew of ID token time claims. Defaults to 1 minute
+func WithLeeway(d time.Duration) OptFn {
+	return func(rp *RelyingParty) {
+		rp.leeway = d
+	}
+}
+
+// WithClock replaces time.Now as source of current time
+func WithClock(clock func() time.Time) OptFn {
+	return func(rp *RelyingParty) {
+		rp.clock = clock
+	}
+}
+
+// Discover fetches provider configuration of issuer
+func Discover(ctx context.Context, client *http.Client, issuer string) (*ProviderMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Join(ErrDiscovery, err)
+	}
+	var provider ProviderMetadata
+	if err = getJSON(client, req, &provider); err != nil {
+		return nil, errors.Join(ErrDiscovery, err)
+	}
+	// issuer of configuration must be identical to issuer it was discovered from
+	if provider.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer %q doesn't match %q", ErrDiscovery, provider.Issuer, issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+	if len(provider.CodeChallengeMethodsSupported) > 0 && !slices.Contains(provider.CodeChallengeMethodsSupported, "S256") {
+		return nil, fmt.Errorf("%w: PKCE S256 not supported", ErrDiscovery)
+	}
+	return &provider, nil
+}
+
+// Provider returns discovered provider configuration
+func (rp *RelyingParty) Provider() *ProviderMetadata {
+	return rp.provider
+}
+
+// Begin sets state and nonce cookies and returns authorization URL user is to be redirected to
+func (rp *RelyingParty) Begin(w http.ResponseWriter, r *http.Request) (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {rp.clientID},
+		"redirect_uri":          {rp.redirectURL},
+		"scope":                 {strings.Join(rp.scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	u, err := url.Parse(rp.provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.Join(ErrDiscovery, err)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&"
+	}
+	u.RawQuery += q.Encode()
+
+	// code verifier is bound to state, so it travels in state cookie
+	expires := rp.clock().Add(rp.timeout)
+	http.SetCookie(w, rp.newCookie(rp.stateCookieName(), state+"."+verifier, expires))
+	http.SetCookie(w, rp.newCookie(rp.nonceCookieName(), nonce, expires))
+	return u.String(), nil
+}
+
+// Exchange handles redirect of provider to redirect URL. It checks state, exchanges code for tokens and
+// verifies ID token, state and nonce cookies are deleted.
+func (rp *RelyingParty) Exchange(w http.ResponseWriter, r *http.Request) (*Token, *Claims, error) {
+	stateCookie, _ := r.Cookie(rp.stateCookieName())
+	nonceCookie, _ := r.Cookie(rp.nonceCookieName())
+	http.SetCookie(w, rp.newCookie(rp.stateCookieName(), "", time.Unix(0, 0)))
+	http.SetCookie(w, rp.newCookie(rp.nonceCookieName(), "", time.Unix(0, 0)))
+
+	q := r.URL.Query()
+	if code := q.Get("error"); code != "" {
+		return nil, nil, fmt.Errorf("%w: %s %s", ErrAuthorizationFailed, code, q.Get("error_description"))
+	}
+	if stateCookie == nil || nonceCookie == nil {
+		return nil, nil, ErrStateMismatch
+	}
+	state, verifier, ok := strings.Cut(stateCookie.Value, ".")
+	if !ok || subtle.ConstantTimeCompare([]byte(state), []byte(q.Get("state"))) != 1 {
+		return nil, nil, ErrStateMismatch
+	}
+	code := q.Get("code")
+	if code == "" {
+		return nil, nil, fmt.Errorf("%w: missing code", ErrAuthorizationFailed)
+	}
+
+	token, err := rp.exchange(r.Context(), code, verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := rp.VerifyIDToken(r.Context(), token.IDToken, nonceCookie.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, claims, nil
+}
+
+// SignIn finishes sign in at redirect URL, maps ID token claims to cookie value and issues auth cookie
+func (rp *RelyingParty) SignIn(w http.ResponseWriter, r *http.Request) (*auth.CookieValue, error) {
+	if rp.mapper == nil {
+		return nil, ErrNoClaimsMapper
+	}
+	_, claims, err := rp.Exchange(w, r)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := rp.mapper(r.Context(), claims)
+	if err != nil {
+		return nil, err
+	}
+	if cv == nil {
+		return nil, ErrNoCookieValue
+	}
+	if cv.Claims.AuthMethod == "" {
+		cv.Claims.AuthMethod = AuthMethodOIDC
+	}
+	if err = auth.IssueCookie(w, r, cv, rp.authConfig); err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
+// exchange exchanges authorization code for tokens at token endpoint
+func (rp *RelyingParty) exchange(ctx context.Context, code, verifier string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {rp.redirectURL},
+		"code_verifier": {verifier},
+	}
+	if rp.clientSecret == "" {
+		form.Set("client_id", rp.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rp.provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Join(ErrTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if rp.clientSecret != "" {
+		// client_secret_basic requires form encoding of credentials, RFC 6749 section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(rp.clientID), url.QueryEscape(rp.clientSecret))
+	}
+
+	var token Token
+	if err = getJSON(rp.client, req, &token); err != nil {
+		return nil, errors.Join(ErrTokenExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrTokenExchange)
+	}
+	return &token, nil
+}
+
+// newCookie creates state or nonce cookie with attributes of auth config
+func (rp *RelyingParty) newCookie(name, value string, expires time.Time) *http.Cookie {
+	cookie := rp.authConfig.NewCookie(name, value)
+	cookie.Expires = expires
+	// Lax, so that cookies are sent on top-level redirect back from provider
+	cookie.SameSite = http.SameSiteLaxMode
+	return cookie
+}
+
+func (rp *RelyingParty) stateCookieName() string {
+	return rp.cookieName + "_state"
+}
+
+func (rp *RelyingParty) nonceCookieName() string {
+	return rp.cookieName + "_nonce"
+}
+
+func randomString() (string, error) {
+	b := make([]byte, randomSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// getJSON sends request and decodes JSON response, error responses of RFC 6749 are reported with their code
+func getJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s %s", e.Error, e.Description)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,422 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/auth"
+)
+
+const (
+	testClientID     = "client"
+	testClientSecret = "secret"
+	testRedirectURL  = "https://app.example/callback"
+)
+
+type (
+	// testProvider is OpenID Connect provider stub, which authorizes every request
+	testProvider struct {
+		*httptest.Server
+		mu      sync.Mutex
+		kid     string
+		key     crypto.Signer
+		codes   map[string]url.Values
+		tamper  func(header, claims map[string]any)
+		discard bool
+	}
+)
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProvider{kid: "rsa-1", key: key, codes: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, ProviderMetadata{
+			Issuer:                           p.URL,
+			AuthorizationEndpoint:            p.URL + "/authorize",
+			TokenEndpoint:                    p.URL + "/token",
+			JWKSURI:                          p.URL + "/jwks",
+			IDTokenSigningAlgValuesSupported: []string{algRS256, algES256},
+			CodeChallengeMethodsSupported:    []string{"S256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, _ *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{p.jwk()}})
+	})
+	mux.HandleFunc("GET /authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		code := rand.Text()
+		p.mu.Lock()
+		p.codes[code] = q
+		p.mu.Unlock()
+		redirect := q.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != testClientID || secret != testClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		code := r.PostFormValue("code")
+		authorization, ok := p.codes[code]
+		delete(p.codes, code)
+		challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+			r.PostFormValue("redirect_uri") != authorization.Get("redirect_uri") ||
+			base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.Get("code_challenge") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		now := time.Now()
+		writeJSON(w, http.StatusOK, Token{
+			AccessToken: "access",
+			TokenType:   "Bearer",
+			ExpiresIn:   3600,
+			IDToken: p.sign(t, map[string]any{
+				"iss":            p.URL,
+				"sub":            "subject",
+				"aud":            testClientID,
+				"exp":            now.Add(time.Minute * 5).Unix(),
+				"iat":            now.Unix(),
+				"nonce":          authorization.Get("nonce"),
+				"email":          "user@example.com",
+				"email_verified": true,
+			}),
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) jwk() map[string]string {
+	switch pub := p.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA", "kid": p.kid, "use": "sig", "alg": algRS256,
+			"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		return map[string]string{
+			"kty": "EC", "kid": p.kid, "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+			"y": base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+	return nil
+}
+
+// sign signs claims by current key of provider, tamper hook may change header and claims before signing
+func (p *testProvider) sign(t *testing.T, claims map[string]any) string {
+	header := map[string]any{"alg": algRS256, "kid": p.kid, "typ": "JWT"}
+	if _, ok := p.key.(*ecdsa.PrivateKey); ok {
+		header["alg"] = algES256
+	}
+	if p.tamper != nil {
+		p.tamper(header, claims)
+	}
+	encode := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Error(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := encode(header) + "." + encode(claims)
+	if p.discard {
+		return signed + "."
+	}
+	sum := sha256.Sum256([]byte(signed))
+	var signature []byte
+	var err error
+	switch key := p.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, sum[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Error(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestRelyingParty(t *testing.T, p *testProvider, opts ...OptFn) *RelyingParty {
+	t.Helper()
+	key, err := auth.NewSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.Must(uuid.NewV4())
+	opts = append([]OptFn{
+		WithHTTPClient(p.Client()),
+		WithAuthConfig(auth.NewConfig(auth.WithSigningKey(key))),
+		WithClaimsMapper(func(_ context.Context, claims *Claims) (*auth.CookieValue, error) {
+			return auth.NewCookieValue(userID, claims.Email, []byte("stamp")), nil
+		}),
+	}, opts...)
+	rp, err := New(t.Context(), p.URL, testClientID, testClientSecret, testRedirectURL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+// authorize begins sign in, follows user to provider and returns request of provider redirect back
+func authorize(t *testing.T, p *testProvider, rp *RelyingParty) *http.Request {
+	t.Helper()
+	w := httptest.NewRecorder()
+	authURL, err := rp.Begin(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, p.URL+"/authorize?") {
+		t.Fatalf("authorization URL = %s", authURL)
+	}
+
+	client := p.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, resp.Header.Get("Location"), nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestSignIn(t *testing.T) {
+	p := newTestProvider(t)
+	rp := newTestRelyingParty(t, p)
+
+	r := authorize(t, p, rp)
+	w := httptest.NewRecorder()
+	cv, err := rp.SignIn(w, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Username != "user@example.com" || cv.Claims.AuthMethod != AuthMethodOIDC {
+		t.Errorf("cookie value = %+v", cv)
+	}
+
+	authRequest := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		switch c.Name {
+		case rp.stateCookieName(), rp.nonceCookieName():
+			if c.Value != "" || c.Expires.After(time.Now()) {
+				t.Errorf("cookie %s was not deleted", c.Name)
+			}
+		default:
+			authRequest.AddCookie(c)
+		}
+	}
+	issued, err := auth.GetCookie(authRequest, rp.authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued.ID != cv.ID || issued.Claims.AuthMethod != AuthMethodOIDC {
+		t.Errorf("issued cookie = %+v", issued)
+	}
+
+	// code is single use
+	if _, err = rp.SignIn(httptest.NewRecorder(), r); !errors.Is(err, ErrTokenExchange) {
+		t.Errorf("SignIn() replay error = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestCookieAttributes(t *testing.T) {
+	p := newTestProvider(t)
+	key, err := auth.NewSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := auth.NewConfig(auth.WithSigningKey(key), auth.WithSecure(false), auth.WithCookieDomain("example.com"))
+	rp := newTestRelyingParty(t, p, WithAuthConfig(cfg))
+
+	w := httptest.NewRecorder()
+	if _, err = rp.Begin(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/login", nil)); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("expected state and nonce cookies, got %d", len(cookies))
+	}
+	for _, c := range cookies {
+		if c.Secure || c.Domain != "example.com" || c.SameSite != http.SameSiteLaxMode || !c.HttpOnly {
+			t.Errorf("cookie %s = %+v", c.Name, c)
+		}
+	}
+}
+
+func TestSignInWithoutCookieValue(t *testing.T) {
+	p := newTestProvider(t)
+	rp := newTestRelyingParty(t, p, WithClaimsMapper(func(context.Context, *Claims) (*auth.CookieValue, error) {
+		return nil, nil
+	}))
+	if _, err := rp.SignIn(httptest.NewRecorder(), authorize(t, p, rp)); !errors.Is(err, ErrNoCookieValue) {
+		t.Errorf("SignIn() error = %v, want ErrNoCookieValue", err)
+	}
+}
+
+func TestExchangeErrors(t *testing.T) {
+	p := newTestProvider(t)
+	rp := newTestRelyingParty(t, p)
+
+	pairs := []struct {
+		name    string
+		request func(r *http.Request)
+		tamper  func(header, claims map[string]any)
+		discard bool
+		err     error
+	}{
+		{name: "state mismatch", request: func(r *http.Request) {
+			q := r.URL.Query()
+			q.Set("state", "other")
+			r.URL.RawQuery = q.Encode()
+		}, err: ErrStateMismatch},
+		{name: "missing cookies", request: func(r *http.Request) {
+			r.Header.Del("Cookie")
+		}, err: ErrStateMismatch},
+		{name: "provider error", request: func(r *http.Request) {
+			r.URL.RawQuery = url.Values{"error": {"access_denied"}}.Encode()
+		}, err: ErrAuthorizationFailed},
+		{name: "wrong verifier", request: func(r *http.Request) {
+			c, _ := r.Cookie(rp.stateCookieName())
+			state, _, _ := strings.Cut(c.Value, ".")
+			r.Header.Del("Cookie")
+			r.AddCookie(&http.Cookie{Name: rp.stateCookieName(), Value: state + ".other"})
+			r.AddCookie(&http.Cookie{Name: rp.nonceCookieName(), Value: "nonce"})
+		}, err: ErrTokenExchange},
+		{name: "nonce mismatch", tamper: func(_, claims map[string]any) {
+			claims["nonce"] = "other"
+		}, err: ErrNonceMismatch},
+		{name: "expired", tamper: func(_, claims map[string]any) {
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		}, err: ErrIDTokenExpired},
+		{name: "wrong audience", tamper: func(_, claims map[string]any) {
+			claims["aud"] = []string{"other"}
+		}, err: ErrInvalidIDToken},
+		{name: "multiple audiences without azp", tamper: func(_, claims map[string]any) {
+			claims["aud"] = []string{testClientID, "other"}
+		}, err: ErrInvalidIDToken},
+		{name: "wrong issuer", tamper: func(_, claims map[string]any) {
+			claims["iss"] = "https://other.example"
+		}, err: ErrInvalidIDToken},
+		{name: "unknown key", tamper: func(header, _ map[string]any) {
+			header["kid"] = "other"
+		}, err: ErrKeyNotFound},
+		{name: "alg none", tamper: func(header, _ map[string]any) {
+			header["alg"] = "none"
+		}, discard: true, err: ErrInvalidIDToken},
+		{name: "invalid signature", tamper: func(_, claims map[string]any) {
+			claims["sub"] = "signed"
+		}, discard: true, err: ErrInvalidIDToken},
+	}
+	for _, pair := range pairs {
+		r := authorize(t, p, rp)
+		if pair.request != nil {
+			pair.request(r)
+		}
+		p.mu.Lock()
+		p.tamper, p.discard = pair.tamper, pair.discard
+		p.mu.Unlock()
+		if _, _, err := rp.Exchange(httptest.NewRecorder(), r); !errors.Is(err, pair.err) {
+			t.Errorf("%s: Exchange() error = %v, want %v", pair.name, err, pair.err)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	p := newTestProvider(t)
+	now := time.Now()
+	rp := newTestRelyingParty(t, p, WithClock(func() time.Time {
+		return now
+	}))
+	if _, _, err := rp.Exchange(httptest.NewRecorder(), authorize(t, p, rp)); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.kid, p.key = "ec-1", key
+	p.mu.Unlock()
+
+	// refresh is rate limited
+	if _, _, err = rp.Exchange(httptest.NewRecorder(), authorize(t, p, rp)); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Exchange() right after refresh error = %v, want ErrKeyNotFound", err)
+	}
+	now = now.Add(minRefreshInterval)
+	_, claims, err := rp.Exchange(httptest.NewRecorder(), authorize(t, p, rp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "subject" || !claims.EmailVerified || claims.Raw["email"] != "user@example.com" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	p := newTestProvider(t)
+	provider, err := Discover(t.Context(), p.Client(), p.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.TokenEndpoint != p.URL+"/token" {
+		t.Errorf("token endpoint = %s", provider.TokenEndpoint)
+	}
+	if _, err = Discover(t.Context(), p.Client(), p.URL+"/"); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("Discover() of mismatched issuer error = %v", err)
+	}
+	cfg := auth.NewConfig()
+	if _, err = New(t.Context(), p.URL+"/missing", testClientID, "", testRedirectURL, WithHTTPClient(p.Client()), WithAuthConfig(cfg)); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("New() of missing provider error = %v", err)
+	}
+	if _, err = New(t.Context(), p.URL, testClientID, "", testRedirectURL, WithHTTPClient(p.Client())); !errors.Is(err, ErrNoAuthConfig) {
+		t.Errorf("New() without auth config error = %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Protector creates URL-safe tokens bound to purpose and security stamp of user, i.e. for password reset,
+// email confirmation or magic link sign in. Token is valid for lifetime of protector and until security stamp
+// changes, so password reset token can't be reused once password is changed.
+type Protector struct {
+	cfg      *Config
+	purpose  string
+	lifetime time.Duration
+}
+
+// protectedTokenV1 is first byte of protected token
+const protectedTokenV1 byte = 6
+
+var (
+	ErrProtectedTokenInvalid = errors.New("auth: Protected token is invalid")
+	ErrProtectedTokenExpired = errors.New("auth: Protected token has expired")
+	ErrNoIdentityStore       = errors.New("auth: No identity store configured")
+)
+
+// NewProtector creates protector signing with keyring of cfg, tokens are encrypted when cfg has encryption enabled.
+// Tokens of one purpose are never valid for another.
+func NewProtector(cfg *Config, purpose string, lifetime time.Duration) *Protector {
+	return &Protector{cfg: cfg, purpose: purpose, lifetime: lifetime}
+}
+
+// Protect creates token for user with security stamp, carrying payload. Payload is readable by token holder
+// unless encryption is enabled.
+func (p *Protector) Protect(id uuid.UUID, securityStamp, payload []byte) (string, error) {
+	kr, err := p.cfg.signingKeyring()
+	if err != nil {
+		return "", err
+	}
+	data := []byte{protectedTokenV1}
+	data = appendTokenBytes(data, []byte(kr.active.id))
+	data = append(data, id[:]...)
+	data = appendTokenTime(data, p.cfg.now().Add(p.lifetime))
+	data = appendTokenBytes(data, payload)
+	signature, err := kr.active.sign(p.digest(data, securityStamp))
+	if err != nil {
+		return "", err
+	}
+	data = appendTokenBytes(data, signature)
+
+	if p.cfg.encryption != nil {
+		if data, err = p.cfg.encryption.seal(data); err != nil {
+			return "", err
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Unprotect verifies token against current security stamp of its user in identity store and returns user ID
+// and payload of token
+func (p *Protector) Unprotect(ctx context.Context, token string) (id uuid.UUID, payload []byte, err error) {
+	if p.cfg.identityStore == nil {
+		return id, nil, ErrNoIdentityStore
+	}
+	id, err = p.UserID(token)
+	if err != nil {
+		return
+	}
+	stamp, err := p.cfg.identityStore.FindByID(ctx, id)
+	if err != nil {
+		return
+	}
+	return p.Verify(token, stamp)
+}
+
+// UserID returns ID of user token was created for, without verifying token. Use it to look up security stamp
+// for Verify when identity store is not configured.
+func (p *Protector) UserID(token string) (id uuid.UUID, err error) {
+	data, err := p.open(token)
+	if err != nil {
+		return
+	}
+	r := &tokenReader{data: data[1:]}
+	r.bytes()
+	copy(id[:], r.fixed(uuid.Size))
+	if r.err != nil {
+		return id, ErrProtectedTokenInvalid
+	}
+	return id, nil
+}
+
+// Verify verifies token against security stamp of its user and returns user ID and payload of token
+func (p *Protector) Verify(token string, securityStamp []byte) (id uuid.UUID, payload []byte, err error) {
+	data, err := p.open(token)
+	if err != nil {
+		return
+	}
+	r := &tokenReader{data: data[1:]}
+	keyID := string(r.bytes())
+	copy(id[:], r.fixed(uuid.Size))
+	expires := r.time()
+	payload = r.bytes()
+	signed := data[:len(data)-len(r.data)]
+	signature := r.bytes()
+	if r.err != nil || len(r.data) > 0 {
+		return id, nil, ErrProtectedTokenInvalid
+	}
+
+	if p.cfg.keyring == nil || !p.cfg.keyring.verify(keyID, p.digest(signed, securityStamp), signature) {
+		return id, nil, ErrProtectedTokenInvalid
+	}
+	if !p.cfg.now().Before(expires) {
+		return id, nil, ErrProtectedTokenExpired
+	}
+	return id, payload, nil
+}
+
+// open decodes and decrypts token
+func (p *Protector) open(token string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) == 0 {
+		return nil, ErrProtectedTokenInvalid
+	}
+	if data[0] == tokenEncryptedV1 {
+		if p.cfg.encryption == nil {
+			return nil, ErrTokenNotDecrypted
+		}
+		if data, err = p.cfg.encryption.open(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 || data[0] != protectedTokenV1 {
+		return nil, ErrProtectedTokenInvalid
+	}
+	return data, nil
+}
+
+// digest binds signed part of token to purpose and security stamp, neither is carried in token
+func (p *Protector) digest(signed, securityStamp []byte) []byte {
+	b := appendTokenBytes([]byte("auth/protector"), []byte(p.purpose))
+	b = appendTokenBytes(b, securityStamp)
+	sum := sha512.Sum512(append(b, signed...))
+	return sum[:]
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestProtector(t *testing.T) {
+	ctx := context.Background()
+	store := &testIdentityStore{stamps: map[uuid.UUID][]byte{}}
+	cfg, clock := newTestConfig(t, WithIdentityStore(store))
+	id := uuid.Must(uuid.NewV4())
+	store.stamps[id] = []byte("stamp")
+
+	reset := NewProtector(cfg, "password-reset", time.Hour)
+	token, err := reset.Protect(id, store.stamps[id], []byte("user@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token is not URL-safe: %s", token)
+	}
+
+	gotID, payload, err := reset.Unprotect(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotID != id || string(payload) != "user@example.com" {
+		t.Errorf("unexpected token content %s %s", gotID, payload)
+	}
+
+	if _, _, err = NewProtector(cfg, "email-confirmation", time.Hour).Unprotect(ctx, token); !errors.Is(err, ErrProtectedTokenInvalid) {
+		t.Errorf("token valid for another purpose, got %v", err)
+	}
+
+	tampered := []byte(token)
+	tampered[len(tampered)/2] ^= 1
+	if _, _, err = reset.Unprotect(ctx, string(tampered)); err == nil {
+		t.Error("tampered token verified")
+	}
+
+	clock.Advance(time.Hour)
+	if _, _, err = reset.Unprotect(ctx, token); !errors.Is(err, ErrProtectedTokenExpired) {
+		t.Errorf("expected expired token, got %v", err)
+	}
+}
+
+func TestProtectorStampChanged(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	id := uuid.Must(uuid.NewV4())
+	p := NewProtector(cfg, "magic-link", 15*time.Minute)
+	token, err := p.Protect(id, []byte("stamp"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = p.Unprotect(context.Background(), token); !errors.Is(err, ErrNoIdentityStore) {
+		t.Errorf("expected ErrNoIdentityStore, got %v", err)
+	}
+	if gotID, err := p.UserID(token); err != nil || gotID != id {
+		t.Fatalf("unexpected user ID %s: %v", gotID, err)
+	}
+	if _, _, err = p.Verify(token, []byte("stamp")); err != nil {
+		t.Error(err)
+	}
+	if _, _, err = p.Verify(token, []byte("changed")); !errors.Is(err, ErrProtectedTokenInvalid) {
+		t.Errorf("token valid after stamp change, got %v", err)
+	}
+}
+
+func TestProtectorEncrypted(t *testing.T) {
+	secret, err := NewEncryptionSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewEncryptionKey(XChaCha20Poly1305, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr, err := NewEncryptionKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := newTestConfig(t, WithEncryption(kr))
+	id := uuid.Must(uuid.NewV4())
+	p := NewProtector(cfg, "email-confirmation", time.Hour)
+	token, err := p.Protect(id, []byte("stamp"), []byte("secret payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, payload, err := p.Verify(token, []byte("stamp"))
+	if err != nil || string(payload) != "secret payload" {
+		t.Errorf("unexpected payload %s: %v", payload, err)
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// IdentityStore looks up current state of identities, i.e. from database
+type IdentityStore interface {
+	// FindByID returns current security stamp of identity. Returns ErrorIdentityNotFound when identity doesn't exist
+	FindByID(ctx context.Context, id uuid.UUID) (securityStamp []byte, err error)
+}
+
+// ValidateCookie validates security stamp of cookie against identity store once validation interval since last
+// validation has passed. Validated cookie is re-issued with fresh ValidatedAt, keeping its expiration. Legacy cookie
+// is validated without being re-issued, like RenewCookie doesn't renew it.
+// Returns ErrorSecurityStampsDiffer when stamp has changed, i.e. after password change, and ErrorIdentityNotFound
+// when identity no longer exists. With TicketStore set, returns ErrTicketNotFound when session was revoked after
+// cookie was read. Does nothing without identity store.
+func ValidateCookie(ctx context.Context, w http.ResponseWriter, cv *CookieValue, cfg *Config) (validated bool, err error) {
+	if validated, err = ValidateToken(ctx, cv, cfg); !validated || cv.legacy {
+		return validated, err
+	}
+	if err = cv.writeCookie(ctx, w, nil, cfg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ValidateToken validates security stamp like ValidateCookie, without re-issuing. Token can't be updated,
+// so its stamp is validated on every use once validation interval since issue has passed.
+func ValidateToken(ctx context.Context, cv *CookieValue, cfg *Config) (validated bool, err error) {
+	if cfg.identityStore == nil {
+		return false, nil
+	}
+	now := cfg.now()
+	if !cv.ValidatedAt.IsZero() && now.Sub(cv.ValidatedAt) < cfg.validationInterval {
+		return false, nil
+	}
+
+	stamp, err := cfg.identityStore.FindByID(ctx, cv.ID)
+	if err != nil {
+		return false, err
+	}
+	if !ValidateSecurityStamp(stamp, cv.SecurityStamp) {
+		return false, ErrorSecurityStampsDiffer
+	}
+	cv.ValidatedAt = now
+	return true, nil
+}
+
+// IsSignOutError reports whether err returned by GetCookie or ValidateCookie means identity must be signed out
+func IsSignOutError(err error) bool {
+	return errors.Is(err, ErrorSecurityStampsDiffer) || errors.Is(err, ErrorIdentityNotFound) ||
+		errors.Is(err, ErrTicketNotFound) || errors.Is(err, ErrLegacyToken)
+}
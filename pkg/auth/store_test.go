@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type testIdentityStore struct {
+	stamps  map[uuid.UUID][]byte
+	lookups int
+}
+
+func (s *testIdentityStore) FindByID(_ context.Context, id uuid.UUID) ([]byte, error) {
+	s.lookups++
+	stamp, ok := s.stamps[id]
+	if !ok {
+		return nil, ErrorIdentityNotFound
+	}
+	return stamp, nil
+}
+
+func TestValidateCookie(t *testing.T) {
+	store := &testIdentityStore{stamps: map[uuid.UUID][]byte{}}
+	cfg, clock := newTestConfig(t, WithIdentityStore(store), WithValidationInterval(10*time.Minute))
+	cookie := issueCookie(t, cfg)
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.stamps[cv.ID] = cv.SecurityStamp
+
+	validate := func() (*CookieValue, bool, error) {
+		t.Helper()
+		cv, err := readCookie(t, cfg, cookie)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		validated, err := ValidateCookie(t.Context(), w, cv, cfg)
+		if validated {
+			cookie = w.Result().Cookies()[0]
+		}
+		return cv, validated, err
+	}
+
+	clock.Advance(5 * time.Minute)
+	if _, validated, err := validate(); validated || err != nil || store.lookups != 0 {
+		t.Errorf("cookie validated within interval: %v", err)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if _, validated, err := validate(); !validated || err != nil || store.lookups != 1 {
+		t.Errorf("cookie not validated after interval: %v", err)
+	}
+	renewed, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !renewed.ValidatedAt.Equal(clock.now) || !renewed.Timestamp.Equal(cv.Timestamp) {
+		t.Errorf("unexpected validated cookie %s, expires %s", renewed.ValidatedAt, renewed.Timestamp)
+	}
+
+	clock.Advance(10 * time.Minute)
+	store.stamps[cv.ID] = []byte("fedcba9876543210")
+	if _, _, err = validate(); !errors.Is(err, ErrorSecurityStampsDiffer) || !IsSignOutError(err) {
+		t.Errorf("expected ErrorSecurityStampsDiffer, got %v", err)
+	}
+
+	delete(store.stamps, cv.ID)
+	if _, _, err = validate(); !errors.Is(err, ErrorIdentityNotFound) || !IsSignOutError(err) {
+		t.Errorf("expected ErrorIdentityNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Ticket is server-side state of session. When TicketStore is set, cookie holds only session ID of ticket
+	Ticket struct {
+		SessionID string
+		Value     *CookieValue
+		// CreatedAt is time of sign in
+		CreatedAt time.Time
+		// ExpiresAt is expiration of cookie, store evicts ticket afterward
+		ExpiresAt time.Time
+		// UserAgent and IPAddress describe device session was created from
+		UserAgent string
+		IPAddress string
+	}
+
+	// TicketStore persists tickets, see NewMemoryTicketStore and NewFileTicketStore
+	TicketStore interface {
+		// Store creates ticket of new session
+		Store(ctx context.Context, t *Ticket) error
+		// Update replaces ticket of existing session. Returns ErrTicketNotFound when ticket was removed or has
+		// expired, so that revoked session isn't written back; check and update must be atomic
+		Update(ctx context.Context, t *Ticket) error
+		// Retrieve returns ticket of session. Returns ErrTicketNotFound when ticket doesn't exist or has expired
+		Retrieve(ctx context.Context, sessionID string) (*Ticket, error)
+		// Remove deletes ticket, removing ticket which doesn't exist is not an error
+		Remove(ctx context.Context, sessionID string) error
+		// ListByUser returns unexpired tickets of user
+		ListByUser(ctx context.Context, id uuid.UUID) ([]*Ticket, error)
+	}
+)
+
+// tokenTicket is first byte of session ID, cookie holding it is resolved through TicketStore
+const tokenTicket byte = 5
+
+// sessionIDSize is number of random bytes in session ID
+const sessionIDSize = 32
+
+var ErrTicketNotFound = errors.New("auth: Session ticket not found")
+
+// newSessionID generates random URL-safe session ID
+func newSessionID() (string, error) {
+	b := make([]byte, 1+sessionIDSize)
+	b[0] = tokenTicket
+	if _, err := rand.Read(b[1:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isSessionID reports whether cookie value is session ID generated by newSessionID
+func isSessionID(s string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil && len(b) == 1+sessionIDSize && b[0] == tokenTicket
+}
+
+// Clone returns deep copy of ticket
+func (t *Ticket) Clone() *Ticket {
+	c := *t
+	if t.Value != nil {
+		cv := *t.Value
+		cv.SecurityStamp = append([]byte(nil), t.Value.SecurityStamp...)
+		cv.Claims = t.Value.Claims.Clone()
+		cv.ticket = nil
+		c.Value = &cv
+	}
+	return &c
+}
+
+func (t *Ticket) expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// storeTicket stores cookie value in ticket store, creating ticket on first write and updating it afterward.
+// Returns ErrTicketNotFound when ticket was revoked meanwhile. Request describes device of new ticket and may be nil.
+func (cv *CookieValue) storeTicket(ctx context.Context, r *http.Request, cfg *Config) error {
+	if cv.ticket != nil {
+		t := *cv.ticket
+		t.Value = cv
+		t.ExpiresAt = cv.Timestamp
+		return cfg.ticketStore.Update(ctx, t.Clone())
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	t := &Ticket{SessionID: id, CreatedAt: cfg.now()}
+	if r != nil {
+		t.UserAgent = r.UserAgent()
+		t.IPAddress = remoteIP(r)
+	}
+	stored := *t
+	stored.Value = cv
+	stored.ExpiresAt = cv.Timestamp
+	if err = cfg.ticketStore.Store(ctx, stored.Clone()); err != nil {
+		return err
+	}
+	cv.ticket = t
+	return nil
+}
+
+// readTicket resolves session ID of cookie to its cookie value
+func (c *Config) readTicket(ctx context.Context, sessionID string) (*CookieValue, error) {
+	t, err := c.ticketStore.Retrieve(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Value == nil || t.expired(c.now()) {
+		return nil, ErrTicketNotFound
+	}
+	cv := t.Clone().Value
+	cv.ticket = t
+	cv.ticket.Value = nil
+	return cv, nil
+}
+
+// SessionID returns session ID of cookie value, empty when ticket store is not used
+func (cv *CookieValue) SessionID() string {
+	if cv.ticket == nil {
+		return ""
+	}
+	return cv.ticket.SessionID
+}
+
+// ListSessions returns active sessions of user. Returns nil without ticket store.
+func ListSessions(ctx context.Context, cfg *Config, id uuid.UUID) ([]*Ticket, error) {
+	if cfg.ticketStore == nil {
+		return nil, nil
+	}
+	return cfg.ticketStore.ListByUser(ctx, id)
+}
+
+// RevokeSession removes ticket of session, cookie holding its session ID is no longer authenticated
+func RevokeSession(ctx context.Context, cfg *Config, sessionID string) error {
+	if cfg.ticketStore == nil {
+		return nil
+	}
+	return cfg.ticketStore.Remove(ctx, sessionID)
+}
+
+// RevokeUserSessions removes every ticket of user, i.e. to sign out everywhere
+func RevokeUserSessions(ctx context.Context, cfg *Config, id uuid.UUID) error {
+	tickets, err := ListSessions(ctx, cfg, id)
+	if err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		if err = cfg.ticketStore.Remove(ctx, t.SessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortTickets sorts tickets by creation time, newest first
+func sortTickets(tickets []*Ticket) {
+	slices.SortFunc(tickets, func(a, b *Ticket) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
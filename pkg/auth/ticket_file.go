@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// FileTicketStore keeps every ticket as JSON file in directory, so that sessions survive restart
+	FileTicketStore struct {
+		ticketStoreConfig
+		dir       string
+		mu        sync.Mutex
+		lastSweep time.Time
+	}
+
+	// ticketFile is persisted form of Ticket, it includes fields CookieValue doesn't serialize
+	ticketFile struct {
+		SessionID        string    `json:"sessionId"`
+		ID               uuid.UUID `json:"id"`
+		Username         string    `json:"username"`
+		SecurityStamp    []byte    `json:"securityStamp"`
+		Timestamp        time.Time `json:"timestamp"`
+		IssuedAt         time.Time `json:"issuedAt"`
+		ValidatedAt      time.Time `json:"validatedAt"`
+		Claims           ClaimSet  `json:"claims"`
+		SessionOnly      bool      `json:"sessionOnly,omitempty"`
+		TwoFactorPending bool      `json:"twoFactorPending,omitempty"`
+		CreatedAt        time.Time `json:"createdAt"`
+		ExpiresAt        time.Time `json:"expiresAt"`
+		UserAgent        string    `json:"userAgent,omitempty"`
+		IPAddress        string    `json:"ipAddress,omitempty"`
+	}
+)
+
+var _ TicketStore = (*FileTicketStore)(nil)
+
+// errMalformedTicket is ErrTicketNotFound of ticket file which can't be decoded, i.e. truncated one
+var errMalformedTicket = fmt.Errorf("%w: malformed ticket file", ErrTicketNotFound)
+
+// NewFileTicketStore creates store keeping tickets in dir, dir is created when it doesn't exist
+func NewFileTicketStore(dir string, opts ...TicketStoreOptFn) (*FileTicketStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileTicketStore{ticketStoreConfig: newTicketStoreConfig(opts), dir: dir}, nil
+}
+
+func (s *FileTicketStore) Store(_ context.Context, t *Ticket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.clock(); s.sweepDue(now, &s.lastSweep) {
+		if err := s.evict(now); err != nil {
+			return err
+		}
+	}
+	return s.write(t)
+}
+
+// Update replaces ticket file while holding lock of store, tickets must not be removed by other process
+func (s *FileTicketStore) Update(_ context.Context, t *Ticket) error {
+	if !isSessionID(t.SessionID) {
+		return ErrTicketNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.read(s.path(t.SessionID))
+	if err != nil {
+		return err
+	}
+	if current.expired(s.clock()) {
+		return ErrTicketNotFound
+	}
+	return s.write(t)
+}
+
+func (s *FileTicketStore) Retrieve(_ context.Context, sessionID string) (*Ticket, error) {
+	if !isSessionID(sessionID) {
+		return nil, ErrTicketNotFound
+	}
+	t, err := s.read(s.path(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	if t.expired(s.clock()) {
+		_ = os.Remove(s.path(sessionID))
+		return nil, ErrTicketNotFound
+	}
+	return t, nil
+}
+
+func (s *FileTicketStore) Remove(_ context.Context, sessionID string) error {
+	if !isSessionID(sessionID) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(sessionID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ListByUser reads every ticket of store, it is meant for modest number of sessions
+func (s *FileTicketStore) ListByUser(_ context.Context, id uuid.UUID) ([]*Ticket, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock()
+	var tickets []*Ticket
+	for _, file := range files {
+		// unreadable ticket is skipped, so that one corrupt file doesn't hide every other session
+		t, err := s.read(file)
+		if err != nil {
+			continue
+		}
+		if t.Value.ID == id && !t.expired(now) {
+			tickets = append(tickets, t)
+		}
+	}
+	sortTickets(tickets)
+	return tickets, nil
+}
+
+// Evict removes expired tickets
+func (s *FileTicketStore) Evict() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evict(s.clock())
+}
+
+func (s *FileTicketStore) evict(now time.Time) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		t, err := s.read(file)
+		if errors.Is(err, errMalformedTicket) {
+			// malformed ticket can never be read, it is removed like expired one
+			if err = os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		} else if err != nil {
+			continue
+		}
+		if t.expired(now) {
+			if err = os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// write writes ticket file, to temporary file first so that readers never see partially written ticket
+func (s *FileTicketStore) write(t *Ticket) error {
+	if !isSessionID(t.SessionID) || t.Value == nil {
+		return ErrTicketNotFound
+	}
+	data, err := json.Marshal(ticketFile{
+		SessionID:        t.SessionID,
+		ID:               t.Value.ID,
+		Username:         t.Value.Username,
+		SecurityStamp:    t.Value.SecurityStamp,
+		Timestamp:        t.Value.Timestamp,
+		IssuedAt:         t.Value.IssuedAt,
+		ValidatedAt:      t.Value.ValidatedAt,
+		Claims:           t.Value.Claims,
+		SessionOnly:      t.Value.SessionOnly,
+		TwoFactorPending: t.Value.TwoFactorPending,
+		CreatedAt:        t.CreatedAt,
+		ExpiresAt:        t.ExpiresAt,
+		UserAgent:        t.UserAgent,
+		IPAddress:        t.IPAddress,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".ticket-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(t.SessionID))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+func (s *FileTicketStore) path(sessionID string) string {
+	return filepath.Join(s.dir, sessionID+".json")
+}
+
+func (s *FileTicketStore) files() ([]string, error) {
+	return filepath.Glob(filepath.Join(s.dir, "*.json"))
+}
+
+// read reads ticket file, missing file is ErrTicketNotFound and file which can't be decoded is errMalformedTicket
+func (s *FileTicketStore) read(name string) (*Ticket, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrTicketNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	var tf ticketFile
+	if err = json.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("%w: %w", errMalformedTicket, err)
+	}
+	return &Ticket{
+		SessionID: tf.SessionID,
+		Value: &CookieValue{
+			ID:               tf.ID,
+			Username:         tf.Username,
+			SecurityStamp:    tf.SecurityStamp,
+			Timestamp:        tf.Timestamp,
+			IssuedAt:         tf.IssuedAt,
+			ValidatedAt:      tf.ValidatedAt,
+			Claims:           tf.Claims,
+			SessionOnly:      tf.SessionOnly,
+			TwoFactorPending: tf.TwoFactorPending,
+		},
+		CreatedAt: tf.CreatedAt,
+		ExpiresAt: tf.ExpiresAt,
+		UserAgent: tf.UserAgent,
+		IPAddress: tf.IPAddress,
+	}, nil
+}
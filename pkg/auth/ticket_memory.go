@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	TicketStoreOptFn  func(*ticketStoreConfig)
+	ticketStoreConfig struct {
+		// sweepInterval is how often expired tickets are evicted, eviction runs on write
+		sweepInterval time.Duration
+		clock         func() time.Time
+	}
+
+	// MemoryTicketStore keeps tickets in memory, tickets are lost on restart
+	MemoryTicketStore struct {
+		ticketStoreConfig
+		mu        sync.Mutex
+		tickets   map[string]*Ticket
+		lastSweep time.Time
+	}
+)
+
+var _ TicketStore = (*MemoryTicketStore)(nil)
+
+func newTicketStoreConfig(opts []TicketStoreOptFn) ticketStoreConfig {
+	cfg := ticketStoreConfig{
+		sweepInterval: time.Minute * 5,
+		clock:         time.Now,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// WithSweepInterval sets how often expired tickets are evicted from store. Defaults to 5 minutes
+func WithSweepInterval(d time.Duration) TicketStoreOptFn {
+	return func(c *ticketStoreConfig) {
+		c.sweepInterval = d
+	}
+}
+
+// WithTicketClock replaces time.Now as source of current time of store
+func WithTicketClock(clock func() time.Time) TicketStoreOptFn {
+	return func(c *ticketStoreConfig) {
+		c.clock = clock
+	}
+}
+
+// sweepDue reports whether expired tickets should be evicted, advancing time of last sweep
+func (c *ticketStoreConfig) sweepDue(now time.Time, lastSweep *time.Time) bool {
+	if now.Sub(*lastSweep) < c.sweepInterval {
+		return false
+	}
+	*lastSweep = now
+	return true
+}
+
+func NewMemoryTicketStore(opts ...TicketStoreOptFn) *MemoryTicketStore {
+	return &MemoryTicketStore{
+		ticketStoreConfig: newTicketStoreConfig(opts),
+		tickets:           make(map[string]*Ticket),
+	}
+}
+
+func (s *MemoryTicketStore) Store(_ context.Context, t *Ticket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	if s.sweepDue(now, &s.lastSweep) {
+		s.evict(now)
+	}
+	s.tickets[t.SessionID] = t.Clone()
+	return nil
+}
+
+func (s *MemoryTicketStore) Update(_ context.Context, t *Ticket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.tickets[t.SessionID]
+	if !ok || current.expired(s.clock()) {
+		return ErrTicketNotFound
+	}
+	s.tickets[t.SessionID] = t.Clone()
+	return nil
+}
+
+func (s *MemoryTicketStore) Retrieve(_ context.Context, sessionID string) (*Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tickets[sessionID]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	if t.expired(s.clock()) {
+		delete(s.tickets, sessionID)
+		return nil, ErrTicketNotFound
+	}
+	return t.Clone(), nil
+}
+
+func (s *MemoryTicketStore) Remove(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tickets, sessionID)
+	return nil
+}
+
+func (s *MemoryTicketStore) ListByUser(_ context.Context, id uuid.UUID) ([]*Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	var tickets []*Ticket
+	for _, t := range s.tickets {
+		if t.Value != nil && t.Value.ID == id && !t.expired(now) {
+			tickets = append(tickets, t.Clone())
+		}
+	}
+	sortTickets(tickets)
+	return tickets, nil
+}
+
+// Evict removes expired tickets
+func (s *MemoryTicketStore) Evict() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(s.clock())
+}
+
+func (s *MemoryTicketStore) evict(now time.Time) {
+	for id, t := range s.tickets {
+		if t.expired(now) {
+			delete(s.tickets, id)
+		}
+	}
+}
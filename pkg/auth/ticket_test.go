@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestTicketStoreCookie(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryTicketStore(WithTicketClock(clock.Now))
+	cfg, _ := newTestConfig(t, WithClock(clock.Now), WithTicketStore(store))
+
+	r := httptest.NewRequest(http.MethodPost, "/login", nil)
+	r.Header.Set("User-Agent", "test-agent")
+	r.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	for i := range 50 {
+		cv.Claims.AddRole("role-" + strconv.Itoa(i))
+	}
+	if err := IssueCookie(w, r, cv, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cookie := w.Result().Cookies()[0]
+	if !isSessionID(cookie.Value) || cookie.Value != cv.SessionID() {
+		t.Fatalf("cookie doesn't hold session ID: %s", cookie.Value)
+	}
+
+	got, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != cv.ID || len(got.Claims.Roles) != 50 || got.SessionID() != cookie.Value {
+		t.Errorf("unexpected cookie value %+v", got)
+	}
+
+	sessions, err := ListSessions(context.Background(), cfg, cv.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].UserAgent != "test-agent" || sessions[0].IPAddress != "192.0.2.1" {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+
+	if err = RevokeSession(context.Background(), cfg, cookie.Value); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readCookie(t, cfg, cookie); !errors.Is(err, ErrTicketNotFound) || !IsSignOutError(err) {
+		t.Errorf("revoked session must not be authenticated, got %v", err)
+	}
+}
+
+func TestTicketStoreRenewKeepsSession(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryTicketStore(WithTicketClock(clock.Now))
+	cfg, _ := newTestConfig(t, WithClock(clock.Now), WithTicketStore(store), IsSliding())
+	cookie := issueCookie(t, cfg)
+
+	clock.Advance(40 * time.Minute)
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	if renewed, err := RenewCookie(context.Background(), w, cv, cfg); err != nil || !renewed {
+		t.Fatalf("cookie not renewed: %v", err)
+	}
+	renewed := w.Result().Cookies()[0]
+	if renewed.Value != cookie.Value {
+		t.Error("renewal must keep session ID")
+	}
+
+	clock.Advance(40 * time.Minute)
+	if _, err = readCookie(t, cfg, renewed); err != nil {
+		t.Errorf("renewed ticket expired: %v", err)
+	}
+}
+
+func TestTicketStoreRenewRevokedSession(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryTicketStore(WithTicketClock(clock.Now))
+	cfg, _ := newTestConfig(t, WithClock(clock.Now), WithTicketStore(store), IsSliding())
+	cookie := issueCookie(t, cfg)
+
+	clock.Advance(40 * time.Minute)
+	cv, err := readCookie(t, cfg, cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// session is revoked while request holding its cookie value is in flight
+	if err = RevokeSession(context.Background(), cfg, cookie.Value); err != nil {
+		t.Fatal(err)
+	}
+	if renewed, err := RenewCookie(context.Background(), httptest.NewRecorder(), cv, cfg); renewed || !IsSignOutError(err) {
+		t.Errorf("revoked session must not be renewed, got %v, %v", renewed, err)
+	}
+	if _, err = store.Retrieve(context.Background(), cookie.Value); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("revoked ticket was written back, got %v", err)
+	}
+}
+
+func TestDeleteCookieRemovesTicket(t *testing.T) {
+	store := NewMemoryTicketStore()
+	cfg, _ := newTestConfig(t, WithTicketStore(store))
+	cookie := issueCookie(t, cfg)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	if err := DeleteCookie(r, httptest.NewRecorder(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Retrieve(context.Background(), cookie.Value); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("ticket not removed, got %v", err)
+	}
+}
+
+func TestTicketStores(t *testing.T) {
+	stores := map[string]func(t *testing.T, clock *testClock) TicketStore{
+		"memory": func(t *testing.T, clock *testClock) TicketStore {
+			return NewMemoryTicketStore(WithTicketClock(clock.Now), WithSweepInterval(time.Minute))
+		},
+		"file": func(t *testing.T, clock *testClock) TicketStore {
+			s, err := NewFileTicketStore(t.TempDir(), WithTicketClock(clock.Now), WithSweepInterval(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+			store := newStore(t, clock)
+
+			userID := uuid.Must(uuid.NewV4())
+			newTicket := func(ttl time.Duration) *Ticket {
+				t.Helper()
+				id, err := newSessionID()
+				if err != nil {
+					t.Fatal(err)
+				}
+				cv := NewCookieValue(userID, "username", []byte("stamp"))
+				cv.Claims.AddRole("admin")
+				cv.Timestamp = clock.now.Add(ttl)
+				ticket := &Ticket{SessionID: id, Value: cv, CreatedAt: clock.now, ExpiresAt: cv.Timestamp, IPAddress: "192.0.2.1"}
+				if err = store.Store(ctx, ticket); err != nil {
+					t.Fatal(err)
+				}
+				return ticket
+			}
+
+			short := newTicket(time.Minute)
+			clock.Advance(time.Second)
+			long := newTicket(time.Hour)
+
+			got, err := store.Retrieve(ctx, long.SessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got.IPAddress = "192.0.2.2"
+			if err = store.Update(ctx, got); err != nil {
+				t.Fatal(err)
+			}
+			if got, err = store.Retrieve(ctx, long.SessionID); err != nil {
+				t.Fatal(err)
+			}
+			if got.Value.ID != userID || !got.Value.Claims.HasRole("admin") || got.IPAddress != "192.0.2.2" {
+				t.Errorf("unexpected ticket %+v", got)
+			}
+			if list, _ := store.ListByUser(ctx, userID); len(list) != 2 || list[0].SessionID != long.SessionID {
+				t.Errorf("expected 2 sessions newest first, got %d", len(list))
+			}
+
+			clock.Advance(2 * time.Minute)
+			if _, err = store.Retrieve(ctx, short.SessionID); !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("expired ticket retrieved, got %v", err)
+			}
+			// write evicts expired tickets after sweep interval
+			newTicket(time.Hour)
+			if list, _ := store.ListByUser(ctx, userID); len(list) != 2 {
+				t.Errorf("expected 2 sessions after eviction, got %d", len(list))
+			}
+
+			if err = store.Remove(ctx, long.SessionID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = store.Retrieve(ctx, long.SessionID); !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("removed ticket retrieved, got %v", err)
+			}
+			if err = store.Update(ctx, long); !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("removed ticket updated, got %v", err)
+			}
+			if _, err = store.Retrieve(ctx, long.SessionID); !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("update wrote back removed ticket, got %v", err)
+			}
+			if _, err = store.Retrieve(ctx, "../../etc/passwd"); !errors.Is(err, ErrTicketNotFound) {
+				t.Errorf("invalid session ID must not be found, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileTicketStoreMalformedFile(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	dir := t.TempDir()
+	store, err := NewFileTicketStore(dir, WithTicketClock(clock.Now), WithSweepInterval(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	junkID, err := newSessionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	junk := store.path(junkID)
+	if err = os.WriteFile(junk, []byte(`{"sessionId":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Retrieve(ctx, junkID); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("malformed ticket must not be found, got %v", err)
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("stamp"))
+	cv.Timestamp = clock.now.Add(time.Hour)
+	// first write runs eviction
+	if err = store.Store(ctx, &Ticket{SessionID: id, Value: cv, CreatedAt: clock.now, ExpiresAt: cv.Timestamp}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(junk); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("malformed ticket not evicted, got %v", err)
+	}
+
+	if err = os.WriteFile(junk, []byte("junk"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := store.ListByUser(ctx, cv.ID); err != nil || len(list) != 1 {
+		t.Errorf("expected session next to malformed ticket, got %d, %v", len(list), err)
+	}
+}
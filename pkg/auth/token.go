@@ -0,0 +1,352 @@
+package auth
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Binary token formats, stored as first byte of token. Legacy tokens are base64 JSON, so they start with `{`.
+// First bytes are shared by every token kind: 2 is tokenEncryptedV1, 5 is tokenTicket and 6 is protectedTokenV1,
+// so formats aren't numbered consecutively.
+const (
+	// tokenBasic is payload without claims
+	tokenBasic byte = 1
+	// tokenWithClaims appends claims to tokenBasic payload
+	tokenWithClaims byte = 3
+	// tokenWithFields appends optional fields to tokenWithClaims payload, it is the format tokens are issued in
+	tokenWithFields byte = 4
+)
+
+// Tags of optional token fields. Unknown fields are skipped, so new fields don't need new token version
+const (
+	tokenFieldValidatedAt uint64 = iota + 1
+	tokenFieldSessionOnly
+	tokenFieldTwoFactorPending
+)
+
+var (
+	ErrMalformedToken = errors.New("auth: Malformed auth token")
+	ErrLegacyToken    = errors.New("auth: Legacy auth token is no longer accepted")
+)
+
+// tokenReader reads fields of binary token, first error stops reading
+type tokenReader struct {
+	data []byte
+	err  error
+}
+
+// encodeAuthToken encodes cv as binary token signed by active key of keyring. Token layout is
+//
+//	version | kid | id (16 bytes) | username | security stamp | timestamp | issued at | claims | fields | signature
+//
+// where variable length fields are prefixed by uvarint length and times are varint unix seconds.
+// Claims are encoded as
+//
+//	roles count | roles... | tenant ID | auth method | values count | (name | value)...
+//
+// with values sorted by name. Optional fields are encoded as
+//
+//	fields count | (tag | value)...
+//
+// Signature covers everything before it.
+func encodeAuthToken(kr *Keyring, cv *CookieValue) (token string, err error) {
+	data, err := signAuthToken(kr, cv)
+	if err != nil {
+		return
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func signAuthToken(kr *Keyring, cv *CookieValue) (token []byte, err error) {
+	if kr == nil {
+		return nil, ErrNoSigningKey
+	}
+	cv.KeyID = kr.active.id
+	cv.Timestamp = cv.Timestamp.UTC().Truncate(time.Second)
+	cv.IssuedAt = cv.IssuedAt.UTC().Truncate(time.Second)
+	cv.ValidatedAt = cv.ValidatedAt.UTC().Truncate(time.Second)
+
+	payload := appendTokenPayload(make([]byte, 0, 128), cv)
+	if cv.Signature, err = kr.active.sign(tokenDigest(payload)); err != nil {
+		return
+	}
+	return appendTokenBytes(payload, cv.Signature), nil
+}
+
+// decodeAuthToken decodes binary token and verifies its signature. Legacy base64 JSON token is decoded only
+// when legacy is set
+func decodeAuthToken(kr *Keyring, s string, legacy bool) (cv *CookieValue, err error) {
+	if data, err := base64.RawURLEncoding.DecodeString(s); err == nil && isBinaryToken(data) {
+		return decodeBinaryToken(kr, data)
+	}
+	if !legacy {
+		return nil, ErrLegacyToken
+	}
+	return decodeLegacyToken(kr, s)
+}
+
+// encodeToken signs cv and encrypts it when encryption is enabled
+func (c *Config) encodeToken(cv *CookieValue) (string, error) {
+	kr, err := c.signingKeyring()
+	if err != nil {
+		return "", err
+	}
+	if c.encryption == nil {
+		return encodeAuthToken(kr, cv)
+	}
+	token, err := signAuthToken(kr, cv)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := c.encryption.seal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decodeToken decrypts and decodes token. Unencrypted tokens are accepted also when encryption is enabled,
+// so that enabling it doesn't sign out every user
+func (c *Config) decodeToken(s string) (*CookieValue, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 || data[0] != tokenEncryptedV1 {
+		return decodeAuthToken(c.keyring, s, c.legacyTokens)
+	}
+	if c.encryption == nil {
+		return nil, ErrTokenNotDecrypted
+	}
+	token, err := c.encryption.open(data)
+	if err != nil {
+		return nil, err
+	}
+	if !isBinaryToken(token) {
+		return nil, ErrMalformedToken
+	}
+	return decodeBinaryToken(c.keyring, token)
+}
+
+func isBinaryToken(data []byte) bool {
+	return len(data) > 0 && (data[0] == tokenBasic || data[0] == tokenWithClaims || data[0] == tokenWithFields)
+}
+
+func decodeBinaryToken(kr *Keyring, data []byte) (*CookieValue, error) {
+	r := &tokenReader{data: data[1:]}
+	cv := &CookieValue{KeyID: string(r.bytes())}
+	copy(cv.ID[:], r.fixed(uuid.Size))
+	cv.Username = string(r.bytes())
+	cv.SecurityStamp = r.bytes()
+	cv.Timestamp = r.time()
+	cv.IssuedAt = r.time()
+	switch data[0] {
+	case tokenWithClaims:
+		cv.Claims = r.claims()
+	case tokenWithFields:
+		cv.Claims = r.claims()
+		r.fields(cv)
+	}
+	payload := data[:len(data)-len(r.data)]
+	cv.Signature = r.bytes()
+	if r.err != nil || len(r.data) > 0 {
+		return nil, ErrMalformedToken
+	}
+
+	if kr == nil || !kr.verify(cv.KeyID, tokenDigest(payload), cv.Signature) {
+		return cv, ErrKeyNotVerified
+	}
+	return cv, nil
+}
+
+func decodeLegacyToken(kr *Keyring, s string) (cv *CookieValue, err error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(decoded, &cv); err != nil {
+		return
+	}
+	// claims are not covered by legacy signature
+	cv.Claims = ClaimSet{}
+	cv.legacy = true
+
+	if kr == nil || !kr.verify(cv.KeyID, cv.Digest(), cv.Signature) {
+		return cv, ErrKeyNotVerified
+	}
+
+	return
+}
+
+func appendTokenPayload(b []byte, cv *CookieValue) []byte {
+	b = append(b, tokenWithFields)
+	b = appendTokenBytes(b, []byte(cv.KeyID))
+	b = append(b, cv.ID[:]...)
+	b = appendTokenBytes(b, []byte(cv.Username))
+	b = appendTokenBytes(b, cv.SecurityStamp)
+	b = appendTokenTime(b, cv.Timestamp)
+	b = appendTokenTime(b, cv.IssuedAt)
+	b = appendTokenClaims(b, cv.Claims)
+	return appendTokenFields(b, cv)
+}
+
+func appendTokenFields(b []byte, cv *CookieValue) []byte {
+	fields := make(map[uint64][]byte)
+	if !cv.ValidatedAt.IsZero() {
+		fields[tokenFieldValidatedAt] = appendTokenTime(nil, cv.ValidatedAt)
+	}
+	if cv.SessionOnly {
+		fields[tokenFieldSessionOnly] = []byte{}
+	}
+	if cv.TwoFactorPending {
+		fields[tokenFieldTwoFactorPending] = []byte{}
+	}
+	b = binary.AppendUvarint(b, uint64(len(fields)))
+	for _, tag := range slices.Sorted(maps.Keys(fields)) {
+		b = binary.AppendUvarint(b, tag)
+		b = appendTokenBytes(b, fields[tag])
+	}
+	return b
+}
+
+func appendTokenClaims(b []byte, cs ClaimSet) []byte {
+	b = binary.AppendUvarint(b, uint64(len(cs.Roles)))
+	for _, role := range cs.Roles {
+		b = appendTokenBytes(b, []byte(role))
+	}
+	b = appendTokenBytes(b, []byte(cs.TenantID))
+	b = appendTokenBytes(b, []byte(cs.AuthMethod))
+	b = binary.AppendUvarint(b, uint64(len(cs.Values)))
+	for _, name := range slices.Sorted(maps.Keys(cs.Values)) {
+		b = appendTokenBytes(b, []byte(name))
+		b = appendTokenBytes(b, []byte(cs.Values[name]))
+	}
+	return b
+}
+
+func appendTokenBytes(b, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendTokenTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(b, 0)
+	}
+	return binary.AppendVarint(b, t.Unix())
+}
+
+// tokenDigest hashes payload, so that whole payload is covered by signature regardless of key type
+func tokenDigest(payload []byte) []byte {
+	sum := sha512.Sum512(payload)
+	return sum[:]
+}
+
+func (r *tokenReader) fixed(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = ErrMalformedToken
+		return nil
+	}
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *tokenReader) bytes() []byte {
+	if r.err != nil {
+		return nil
+	}
+	n, size := binary.Uvarint(r.data)
+	if size <= 0 || n > uint64(len(r.data)-size) {
+		r.err = ErrMalformedToken
+		return nil
+	}
+	r.data = r.data[size:]
+	return r.fixed(int(n))
+}
+
+func (r *tokenReader) count() int {
+	if r.err != nil {
+		return 0
+	}
+	n, size := binary.Uvarint(r.data)
+	// every counted item takes at least one byte
+	if size <= 0 || n > uint64(len(r.data)-size) {
+		r.err = ErrMalformedToken
+		return 0
+	}
+	r.data = r.data[size:]
+	return int(n)
+}
+
+func (r *tokenReader) claims() ClaimSet {
+	var cs ClaimSet
+	if n := r.count(); n > 0 {
+		cs.Roles = make([]string, n)
+		for i := range n {
+			cs.Roles[i] = string(r.bytes())
+		}
+	}
+	cs.TenantID = string(r.bytes())
+	cs.AuthMethod = string(r.bytes())
+	if n := r.count(); n > 0 {
+		cs.Values = make(map[string]string, n)
+		for range n {
+			name := string(r.bytes())
+			cs.Values[name] = string(r.bytes())
+		}
+	}
+	return cs
+}
+
+func (r *tokenReader) fields(cv *CookieValue) {
+	for range r.count() {
+		tag, size := binary.Uvarint(r.data)
+		if r.err != nil || size <= 0 {
+			r.err = ErrMalformedToken
+			return
+		}
+		r.data = r.data[size:]
+		value := &tokenReader{data: r.bytes()}
+		switch tag {
+		case tokenFieldValidatedAt:
+			cv.ValidatedAt = value.time()
+		case tokenFieldSessionOnly:
+			cv.SessionOnly = true
+		case tokenFieldTwoFactorPending:
+			cv.TwoFactorPending = true
+		default:
+			// fields added later are skipped
+			continue
+		}
+		if value.err != nil || len(value.data) > 0 {
+			r.err = ErrMalformedToken
+			return
+		}
+	}
+}
+
+func (r *tokenReader) time() time.Time {
+	if r.err != nil {
+		return time.Time{}
+	}
+	v, size := binary.Varint(r.data)
+	if size <= 0 {
+		r.err = ErrMalformedToken
+		return time.Time{}
+	}
+	r.data = r.data[size:]
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(v, 0).UTC()
+}
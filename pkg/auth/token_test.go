@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestAuthTokenV1(t *testing.T) {
+	kr := NewConfig(WithSigningKey(getEcdsaKey(t))).keyring
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", bytes.Repeat([]byte("stamp"), 8))
+	cv.Timestamp = time.Date(2025, 1, 1, 12, 0, 0, 500, time.UTC)
+	cv.IssuedAt = time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	token, err := encodeAuthToken(kr, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeAuthToken(kr, token, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != cv.ID || decoded.Username != cv.Username || !bytes.Equal(decoded.SecurityStamp, cv.SecurityStamp) ||
+		!decoded.Timestamp.Equal(cv.Timestamp) || !decoded.IssuedAt.Equal(cv.IssuedAt) || decoded.KeyID != kr.Active().ID() {
+		t.Errorf("decoded token %+v doesn't match %+v", decoded, cv)
+	}
+
+	legacy, err := signLegacyToken(kr, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) >= len(legacy) {
+		t.Errorf("binary token (%d) is not smaller than legacy token (%d)", len(token), len(legacy))
+	}
+	if _, err = decodeAuthToken(kr, legacy, true); err != nil {
+		t.Errorf("legacy token not accepted: %v", err)
+	}
+}
+
+func TestLegacyTokensDisabled(t *testing.T) {
+	key := getEcdsaKey(t)
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("stamp"))
+	cv.Timestamp = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	cfg := NewConfig(WithSigningKey(key), WithLegacyTokens(false))
+	legacy, err := signLegacyToken(cfg.keyring, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewConfig(WithKeyring(cfg.keyring)).decodeToken(legacy); err != nil {
+		t.Errorf("legacy token not accepted by default: %v", err)
+	}
+	if _, err = cfg.decodeToken(legacy); !errors.Is(err, ErrLegacyToken) || !IsSignOutError(err) {
+		t.Errorf("legacy token error = %v, want ErrLegacyToken", err)
+	}
+
+	token, err := cfg.encodeToken(cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cfg.decodeToken(token); err != nil {
+		t.Errorf("binary token not accepted: %v", err)
+	}
+}
+
+func TestLegacyTokenNotReissued(t *testing.T) {
+	store := &testIdentityStore{stamps: map[uuid.UUID][]byte{}}
+	cfg, clock := newTestConfig(t, IsSliding(), WithIdentityStore(store), WithValidationInterval(0))
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.Timestamp = clock.now.Add(time.Minute)
+	store.stamps[cv.ID] = cv.SecurityStamp
+	legacy, err := signLegacyToken(cfg.keyring, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := cfg.decodeToken(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	if renewed, err := RenewCookie(t.Context(), w, decoded, cfg); renewed || err != nil {
+		t.Errorf("legacy cookie renewed: %v, %v", renewed, err)
+	}
+	if validated, err := ValidateCookie(t.Context(), w, decoded, cfg); !validated || err != nil {
+		t.Errorf("legacy cookie not validated: %v, %v", validated, err)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("legacy cookie re-issued")
+	}
+}
+
+func TestAuthTokenV1Tampered(t *testing.T) {
+	kr := NewConfig(WithSigningKey(getEcdsaKey(t))).keyring
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef-long-stamp"))
+	cv.Timestamp = time.Now()
+	token, err := encodeAuthToken(kr, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.RawURLEncoding.DecodeString(token)
+
+	payloadLen := len(data) - len(cv.Signature) - 1
+	for i := 1; i < payloadLen; i++ {
+		tampered := bytes.Clone(data)
+		tampered[i] ^= 0x01
+		_, err = decodeAuthToken(kr, base64.RawURLEncoding.EncodeToString(tampered), true)
+		if !errors.Is(err, ErrKeyNotVerified) && !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("tampered byte %d accepted: %v", i, err)
+		}
+	}
+
+	for _, truncated := range [][]byte{data[:1], data[:20], data[:len(data)-1], append(bytes.Clone(data), 0)} {
+		if _, err = decodeAuthToken(kr, base64.RawURLEncoding.EncodeToString(truncated), true); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("expected ErrMalformedToken for %d bytes, got %v", len(truncated), err)
+		}
+	}
+}
+
+// signLegacyToken creates base64 JSON token as issued before binary format
+func signLegacyToken(kr *Keyring, cv *CookieValue) (string, error) {
+	c := *cv
+	c.KeyID = kr.Active().ID()
+	sig, err := kr.Active().sign(c.Digest())
+	if err != nil {
+		return "", err
+	}
+	c.Signature = sig
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,50 @@
+package totp
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"slices"
+	"strings"
+
+	"github.com/pudottapommin/golib/pkg/hasher"
+)
+
+// recoveryCodeSize is number of random bytes of recovery code, encoded as 10 characters
+const recoveryCodeSize = 6
+
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes generates n one-time recovery codes formatted as `xxxxx-xxxxx`. Codes are shown to user once,
+// only their hashes are stored.
+func GenerateRecoveryCodes(h hasher.Hasher, n int) (codes, hashes []string, err error) {
+	codes = make([]string, n)
+	hashes = make([]string, n)
+	b := make([]byte, recoveryCodeSize)
+	for i := range n {
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryEncoding.EncodeToString(b)
+		if hashes[i], err = h.Hash(code); err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, hashes, nil
+}
+
+// UseRecoveryCode verifies code against stored hashes. When code matches, remaining hashes without used one
+// are returned and must replace stored hashes, so that code can't be used again.
+func UseRecoveryCode(h hasher.Hasher, hashes []string, code string) (remaining []string, ok bool, err error) {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	for i, hash := range hashes {
+		result, err := h.Verify(hash, code)
+		if err != nil {
+			return hashes, false, err
+		}
+		if result != hasher.PasswordVerificationFailed {
+			return slices.Delete(slices.Clone(hashes), i, i+1), true, nil
+		}
+	}
+	return hashes, false, nil
+}
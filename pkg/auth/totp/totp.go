@@ -0,0 +1,146 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	Algorithm uint8
+
+	// Key is shared TOTP secret of user with its parameters, zero parameters use defaults of authenticator apps,
+	// SHA1 with 6 digits and 30 second period
+	Key struct {
+		Secret      []byte
+		Issuer      string
+		AccountName string
+		Algorithm   Algorithm
+		Digits      int
+		Period      time.Duration
+	}
+)
+
+const (
+	AlgorithmSHA1 Algorithm = iota
+	AlgorithmSHA256
+	AlgorithmSHA512
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30 * time.Second
+	// secretSize is size of generated secret, RFC 4226 recommends 160 bits
+	secretSize = 20
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generate generates key with random secret for account of issuer
+func Generate(issuer, accountName string) (*Key, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &Key{Secret: secret, Issuer: issuer, AccountName: accountName}, nil
+}
+
+// ParseSecret decodes base32 secret as shown to user, i.e. for manual entry
+func ParseSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return secretEncoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// EncodedSecret returns base32 secret for manual entry into authenticator app
+func (k *Key) EncodedSecret() string {
+	return secretEncoding.EncodeToString(k.Secret)
+}
+
+// URI returns `otpauth://` provisioning URI, usually shown to user as QR code
+func (k *Key) URI() string {
+	label := url.PathEscape(k.AccountName)
+	if k.Issuer != "" {
+		label = url.PathEscape(k.Issuer) + ":" + label
+	}
+	q := url.Values{}
+	q.Set("secret", k.EncodedSecret())
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	q.Set("algorithm", k.Algorithm.String())
+	q.Set("digits", strconv.Itoa(k.digits()))
+	q.Set("period", strconv.Itoa(int(k.period()/time.Second)))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Code returns code valid at time t
+func (k *Key) Code(t time.Time) string {
+	return k.codeAt(k.Step(t))
+}
+
+// Step returns time step of t, i.e. number of periods since Unix epoch
+func (k *Key) Step(t time.Time) int64 {
+	return t.Unix() / int64(k.period()/time.Second)
+}
+
+// codeAt computes HOTP value of RFC 4226 for counter step
+func (k *Key) codeAt(step int64) string {
+	mac := hmac.New(k.Algorithm.hash(), k.Secret)
+	_ = binary.Write(mac, binary.BigEndian, step)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := int64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+
+	digits := k.digits()
+	mod := int64(1)
+	for range digits {
+		mod *= 10
+	}
+	code := strconv.FormatInt(value%mod, 10)
+	return strings.Repeat("0", digits-len(code)) + code
+}
+
+func (k *Key) digits() int {
+	if k.Digits > 0 {
+		return k.Digits
+	}
+	return DefaultDigits
+}
+
+func (k *Key) period() time.Duration {
+	if k.Period >= time.Second {
+		return k.Period
+	}
+	return DefaultPeriod
+}
+
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmSHA256:
+		return "SHA256"
+	case AlgorithmSHA512:
+		return "SHA512"
+	default:
+		return "SHA1"
+	}
+}
+
+func (a Algorithm) hash() func() hash.Hash {
+	switch a {
+	case AlgorithmSHA256:
+		return sha256.New
+	case AlgorithmSHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,110 @@
+package totp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/hasher"
+)
+
+// Test vectors of RFC 6238 appendix B
+func TestCodeRFC6238(t *testing.T) {
+	pairs := []struct {
+		alg    Algorithm
+		secret string
+		time   int64
+		code   string
+	}{
+		{AlgorithmSHA1, "12345678901234567890", 59, "94287082"},
+		{AlgorithmSHA256, "12345678901234567890123456789012", 59, "46119246"},
+		{AlgorithmSHA512, "1234567890123456789012345678901234567890123456789012345678901234", 59, "90693936"},
+		{AlgorithmSHA1, "12345678901234567890", 1111111109, "07081804"},
+		{AlgorithmSHA1, "12345678901234567890", 20000000000, "65353130"},
+		{AlgorithmSHA256, "12345678901234567890123456789012", 1234567890, "91819424"},
+		{AlgorithmSHA512, "1234567890123456789012345678901234567890123456789012345678901234", 2000000000, "38618901"},
+	}
+	for _, p := range pairs {
+		k := &Key{Secret: []byte(p.secret), Algorithm: p.alg, Digits: 8}
+		if code := k.Code(time.Unix(p.time, 0)); code != p.code {
+			t.Errorf("%s at %d: expected %s, got %s", p.alg, p.time, p.code, code)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	k, err := Generate("Example Co", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(k.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Example Co:user@example.com" {
+		t.Errorf("unexpected URI %s", u)
+	}
+	q := u.Query()
+	if q.Get("issuer") != "Example Co" || q.Get("digits") != "6" || q.Get("period") != "30" || q.Get("algorithm") != "SHA1" {
+		t.Errorf("unexpected parameters %s", q.Encode())
+	}
+	secret, err := ParseSecret(q.Get("secret"))
+	if err != nil || string(secret) != string(k.Secret) {
+		t.Errorf("secret doesn't round trip: %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	v := NewVerifier(WithClock(func() time.Time { return now }), WithSkew(1))
+	k, err := Generate("issuer", "account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := uuid.Must(uuid.NewV4())
+
+	if err = v.Verify(ctx, id, k, k.Code(now.Add(-30*time.Second))); err != nil {
+		t.Errorf("code within skew rejected: %v", err)
+	}
+	if err = v.Verify(ctx, id, k, k.Code(now.Add(-90*time.Second))); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("code outside skew accepted: %v", err)
+	}
+	if err = v.Verify(ctx, id, k, k.Code(now)); err != nil {
+		t.Errorf("current code rejected: %v", err)
+	}
+	if err = v.Verify(ctx, id, k, k.Code(now)); !errors.Is(err, ErrCodeReused) {
+		t.Errorf("replayed code accepted: %v", err)
+	}
+	if err = v.Verify(ctx, id, k, k.Code(now.Add(-30*time.Second))); !errors.Is(err, ErrCodeReused) {
+		t.Errorf("code older than used one accepted: %v", err)
+	}
+	if err = v.Verify(ctx, uuid.Must(uuid.NewV4()), k, k.Code(now)); err != nil {
+		t.Errorf("code of another user rejected: %v", err)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	h := hasher.New()
+	codes, hashes, err := GenerateRecoveryCodes(h, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 3 || len(codes[0]) != 11 || codes[0][5] != '-' {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+
+	remaining, ok, err := UseRecoveryCode(h, hashes, " "+codes[1]+" ")
+	if err != nil || !ok || len(remaining) != 2 {
+		t.Fatalf("recovery code rejected: %v", err)
+	}
+	if _, ok, _ = UseRecoveryCode(h, remaining, codes[1]); ok {
+		t.Error("used recovery code accepted")
+	}
+	if _, ok, _ = UseRecoveryCode(h, remaining, "aaaaa-aaaaa"); ok {
+		t.Error("unknown recovery code accepted")
+	}
+}
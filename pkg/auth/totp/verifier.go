@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	OptFn    func(*Verifier)
+	Verifier struct {
+		// skew is number of periods before and after current one codes are accepted from
+		skew  int
+		steps StepStore
+		clock func() time.Time
+	}
+
+	// StepStore records time step of last code each user signed in with, so that code can't be used twice
+	StepStore interface {
+		// UseStep records step as used by user. Returns false when user already used step or any later one,
+		// check and update must be atomic
+		UseStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	}
+
+	// MemoryStepStore keeps used steps in memory, use shared store when running multiple instances
+	MemoryStepStore struct {
+		mu    sync.Mutex
+		steps map[uuid.UUID]int64
+	}
+)
+
+var (
+	ErrInvalidCode = errors.New("totp: Invalid code")
+	ErrCodeReused  = errors.New("totp: Code was already used")
+)
+
+var _ StepStore = (*MemoryStepStore)(nil)
+
+func NewVerifier(opts ...OptFn) *Verifier {
+	v := &Verifier{
+		skew:  1,
+		steps: nil,
+		clock: time.Now,
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	if v.steps == nil {
+		v.steps = NewMemoryStepStore()
+	}
+	return v
+}
+
+// WithSkew sets number of periods before and after current one codes are accepted from, to tolerate clock drift
+// of device. Defaults to 1
+func WithSkew(periods int) OptFn {
+	return func(v *Verifier) {
+		v.skew = max(periods, 0)
+	}
+}
+
+// WithStepStore sets store of used steps. Defaults to MemoryStepStore
+func WithStepStore(store StepStore) OptFn {
+	return func(v *Verifier) {
+		v.steps = store
+	}
+}
+
+// WithClock replaces time.Now as source of current time
+func WithClock(clock func() time.Time) OptFn {
+	return func(v *Verifier) {
+		v.clock = clock
+	}
+}
+
+// Verify verifies code of user's key. Returns ErrInvalidCode when code doesn't match any step within skew window
+// and ErrCodeReused when code, or code of later step, was already used.
+func (v *Verifier) Verify(ctx context.Context, id uuid.UUID, key *Key, code string) error {
+	current := key.Step(v.clock())
+	for offset := -v.skew; offset <= v.skew; offset++ {
+		step := current + int64(offset)
+		if subtle.ConstantTimeCompare([]byte(key.codeAt(step)), []byte(code)) != 1 {
+			continue
+		}
+		ok, err := v.steps.UseStep(ctx, id, step)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCodeReused
+		}
+		return nil
+	}
+	return ErrInvalidCode
+}
+
+func NewMemoryStepStore() *MemoryStepStore {
+	return &MemoryStepStore{steps: make(map[uuid.UUID]int64)}
+}
+
+func (s *MemoryStepStore) UseStep(_ context.Context, id uuid.UUID, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.steps[id]; ok && step <= last {
+		return false, nil
+	}
+	s.steps[id] = step
+	return true, nil
+}
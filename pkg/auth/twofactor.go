@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"net/http"
+)
+
+// IssueTwoFactorPendingCookie issues cookie for user who verified password but not yet second factor.
+// Cookie expires after timeout set by WithTwoFactorTimeout and is not renewed.
+func IssueTwoFactorPendingCookie(w http.ResponseWriter, r *http.Request, cv *CookieValue, cfg *Config) error {
+	cv.TwoFactorPending = true
+	return IssueCookie(w, r, cv, cfg)
+}
+
+// CompleteTwoFactor re-issues cookie pending second factor as authenticated cookie with AuthMethodMFA, once second
+// factor was verified. With TicketStore set, pending session is replaced by new one.
+func CompleteTwoFactor(w http.ResponseWriter, r *http.Request, cv *CookieValue, cfg *Config) error {
+	if cv.ticket != nil {
+		if err := cfg.ticketStore.Remove(r.Context(), cv.ticket.SessionID); err != nil {
+			return err
+		}
+		cv.ticket = nil
+	}
+	cv.TwoFactorPending = false
+	cv.Claims.AuthMethod = AuthMethodMFA
+	return IssueCookie(w, r, cv, cfg)
+}
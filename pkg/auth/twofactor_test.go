@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestTwoFactorPendingCookie(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryTicketStore(WithTicketClock(clock.Now))
+	cfg, _ := newTestConfig(t, WithClock(clock.Now), WithTicketStore(store), IsSliding())
+	r := httptest.NewRequest(http.MethodPost, "/login", nil)
+	w := httptest.NewRecorder()
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	if err := IssueTwoFactorPendingCookie(w, r, cv, cfg); err != nil {
+		t.Fatal(err)
+	}
+	pending := w.Result().Cookies()[0]
+
+	got, err := readCookie(t, cfg, pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.TwoFactorPending || !got.Timestamp.Equal(clock.now.Add(5*time.Minute)) {
+		t.Errorf("unexpected pending cookie %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	if err = CompleteTwoFactor(w, r, got, cfg); err != nil {
+		t.Fatal(err)
+	}
+	completed := w.Result().Cookies()[0]
+	if completed.Value == pending.Value {
+		t.Error("session not replaced on completion")
+	}
+	if _, err = readCookie(t, cfg, pending); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("pending session still valid, got %v", err)
+	}
+	got, err = readCookie(t, cfg, completed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TwoFactorPending || got.Claims.AuthMethod != AuthMethodMFA || !got.Timestamp.Equal(clock.now.Add(time.Hour)) {
+		t.Errorf("unexpected completed cookie %+v", got)
+	}
+}
+
+func TestTwoFactorPendingToken(t *testing.T) {
+	cfg, _ := newTestConfig(t)
+	cv := NewCookieValue(uuid.Must(uuid.NewV4()), "username", []byte("0123456789abcdef"))
+	cv.TwoFactorPending = true
+	cv.SessionOnly = true
+	token, err := encodeAuthToken(cfg.keyring, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeAuthToken(cfg.keyring, token, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.TwoFactorPending || !got.SessionOnly {
+		t.Errorf("flags not carried in token %+v", got)
+	}
+}
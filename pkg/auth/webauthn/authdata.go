@@ -0,0 +1,78 @@
+package webauthn
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+)
+
+// Flags of authenticator data
+const (
+	flagUserPresent      byte = 1 << 0
+	flagUserVerified     byte = 1 << 2
+	flagAttestedCredData byte = 1 << 6
+	flagExtensionData    byte = 1 << 7
+)
+
+// authDataMinSize is size of rpIdHash, flags and signCount
+const authDataMinSize = 37
+
+var ErrMalformedAuthData = errors.New("webauthn: Malformed authenticator data")
+
+// authenticatorData is parsed authenticator data, credential fields are set when it carries attested credential
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	aaguid       []byte
+	credentialID []byte
+	// publicKey is COSE_Key of credential as sent by authenticator
+	publicKey    []byte
+	publicKeyAlg Algorithm
+	key          crypto.PublicKey
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < authDataMinSize {
+		return nil, ErrMalformedAuthData
+	}
+	ad := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[authDataMinSize:]
+	if ad.flags&flagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, ErrMalformedAuthData
+		}
+		ad.aaguid = rest[:16]
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < n {
+			return nil, ErrMalformedAuthData
+		}
+		ad.credentialID, rest = rest[:n], rest[n:]
+
+		var (
+			after []byte
+			err   error
+		)
+		if ad.key, ad.publicKeyAlg, after, err = parsePublicKey(rest); err != nil {
+			return nil, err
+		}
+		ad.publicKey, rest = rest[:len(rest)-len(after)], after
+	}
+	if ad.flags&flagExtensionData != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrMalformedAuthData
+		}
+		rest = after
+	}
+	if len(rest) > 0 {
+		return nil, ErrMalformedAuthData
+	}
+	return ad, nil
+}
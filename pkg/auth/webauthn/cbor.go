@@ -0,0 +1,143 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// maxCBORDepth limits nesting of decoded items
+const maxCBORDepth = 16
+
+var ErrMalformedCBOR = errors.New("webauthn: Malformed CBOR")
+
+// decodeCBOR decodes first CBOR item of data and returns bytes following it. Only definite length items
+// WebAuthn uses are supported: integers as int64, byte and text strings, arrays as []any, maps as map[any]any,
+// booleans and null.
+func decodeCBOR(data []byte) (v any, rest []byte, err error) {
+	d := &cborDecoder{data: data}
+	v = d.item(0)
+	if d.err != nil {
+		return nil, nil, d.err
+	}
+	return v, d.data, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *cborDecoder) item(depth int) any {
+	if d.err != nil {
+		return nil
+	}
+	if depth > maxCBORDepth || len(d.data) == 0 {
+		d.err = ErrMalformedCBOR
+		return nil
+	}
+	major, info := d.data[0]>>5, d.data[0]&0x1f
+	d.data = d.data[1:]
+	if major == 7 {
+		switch info {
+		case 20:
+			return false
+		case 21:
+			return true
+		case 22:
+			return nil
+		}
+		d.err = ErrMalformedCBOR
+		return nil
+	}
+
+	n := d.argument(info)
+	if d.err != nil {
+		return nil
+	}
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			break
+		}
+		return int64(n)
+	case 1:
+		if n > math.MaxInt64 {
+			break
+		}
+		return -1 - int64(n)
+	case 2:
+		return d.bytes(n)
+	case 3:
+		b := d.bytes(n)
+		if d.err != nil {
+			return nil
+		}
+		return string(b)
+	case 4:
+		if n > uint64(len(d.data)) {
+			break
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = d.item(depth + 1)
+		}
+		return items
+	case 5:
+		if n > uint64(len(d.data)) {
+			break
+		}
+		items := make(map[any]any, n)
+		for range n {
+			k := d.item(depth + 1)
+			switch k.(type) {
+			case int64, string:
+			default:
+				d.err = ErrMalformedCBOR
+				return nil
+			}
+			items[k] = d.item(depth + 1)
+		}
+		return items
+	}
+	d.err = ErrMalformedCBOR
+	return nil
+}
+
+// argument reads argument of item header, indefinite length is not supported
+func (d *cborDecoder) argument(info byte) uint64 {
+	size := 0
+	switch {
+	case info < 24:
+		return uint64(info)
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		d.err = ErrMalformedCBOR
+		return 0
+	}
+	if len(d.data) < size {
+		d.err = ErrMalformedCBOR
+		return 0
+	}
+	var b [8]byte
+	copy(b[8-size:], d.data[:size])
+	d.data = d.data[size:]
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (d *cborDecoder) bytes(n uint64) []byte {
+	if n > uint64(len(d.data)) {
+		d.err = ErrMalformedCBOR
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
@@ -0,0 +1,97 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// Algorithm is COSE algorithm identifier
+type Algorithm int64
+
+const (
+	AlgorithmES256 Algorithm = -7
+	AlgorithmEdDSA Algorithm = -8
+)
+
+// COSE key parameters of RFC 9053
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	coseCurve     = -1
+	coseX         = -2
+	coseY         = -3
+
+	coseKeyTypeOKP   = 1
+	coseKeyTypeEC2   = 2
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmES256:
+		return "ES256"
+	case AlgorithmEdDSA:
+		return "EdDSA"
+	}
+	return "unknown"
+}
+
+var ErrUnsupportedKey = errors.New("webauthn: Unsupported credential public key")
+
+// parsePublicKey parses COSE_Key of ES256 or EdDSA credential and returns bytes following it
+func parsePublicKey(data []byte) (crypto.PublicKey, Algorithm, []byte, error) {
+	v, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, 0, nil, ErrUnsupportedKey
+	}
+	kty, _ := m[int64(coseKeyType)].(int64)
+	alg, _ := m[int64(coseAlgorithm)].(int64)
+	crv, _ := m[int64(coseCurve)].(int64)
+	x, _ := m[int64(coseX)].([]byte)
+
+	switch {
+	case kty == coseKeyTypeEC2 && Algorithm(alg) == AlgorithmES256 && crv == coseCurveP256:
+		y, _ := m[int64(coseY)].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, 0, nil, ErrUnsupportedKey
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, 0, nil, ErrUnsupportedKey
+		}
+		return key, AlgorithmES256, rest, nil
+	case kty == coseKeyTypeOKP && Algorithm(alg) == AlgorithmEdDSA && crv == coseCurveEd25519:
+		if len(x) != ed25519.PublicKeySize {
+			return nil, 0, nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), AlgorithmEdDSA, rest, nil
+	}
+	return nil, 0, nil, ErrUnsupportedKey
+}
+
+// verifySignature verifies signature of data by key of algorithm
+func verifySignature(key crypto.PublicKey, alg Algorithm, data, signature []byte) bool {
+	switch alg {
+	case AlgorithmES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		sum := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(pub, sum[:], signature)
+	case AlgorithmEdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(pub, data, signature)
+	}
+	return false
+}
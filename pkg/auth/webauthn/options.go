@@ -0,0 +1,70 @@
+package webauthn
+
+// JSON shapes of WebAuthn Level 3, as consumed by `PublicKeyCredential.parseCreationOptionsFromJSON()` and
+// `PublicKeyCredential.parseRequestOptionsFromJSON()` and produced by `PublicKeyCredential.toJSON()`
+type (
+	CreationOptions struct {
+		RP                     rpEntity               `json:"rp"`
+		User                   userEntity             `json:"user"`
+		Challenge              Base64URL              `json:"challenge"`
+		PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+		Timeout                int64                  `json:"timeout,omitempty"`
+		ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials,omitempty"`
+		AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+		Attestation            string                 `json:"attestation"`
+	}
+
+	RequestOptions struct {
+		Challenge        Base64URL              `json:"challenge"`
+		Timeout          int64                  `json:"timeout,omitempty"`
+		RPID             string                 `json:"rpId"`
+		AllowCredentials []credentialDescriptor `json:"allowCredentials,omitempty"`
+		UserVerification UserVerification       `json:"userVerification"`
+	}
+
+	RegistrationResponse struct {
+		ID       string    `json:"id"`
+		RawID    Base64URL `json:"rawId"`
+		Type     string    `json:"type"`
+		Response struct {
+			ClientDataJSON    Base64URL `json:"clientDataJSON"`
+			AttestationObject Base64URL `json:"attestationObject"`
+		} `json:"response"`
+	}
+
+	AssertionResponse struct {
+		ID       string    `json:"id"`
+		RawID    Base64URL `json:"rawId"`
+		Type     string    `json:"type"`
+		Response struct {
+			ClientDataJSON    Base64URL `json:"clientDataJSON"`
+			AuthenticatorData Base64URL `json:"authenticatorData"`
+			Signature         Base64URL `json:"signature"`
+			UserHandle        Base64URL `json:"userHandle,omitempty"`
+		} `json:"response"`
+	}
+
+	rpEntity struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	userEntity struct {
+		ID          Base64URL `json:"id"`
+		Name        string    `json:"name"`
+		DisplayName string    `json:"displayName"`
+	}
+	credentialParameter struct {
+		Type string    `json:"type"`
+		Alg  Algorithm `json:"alg"`
+	}
+	credentialDescriptor struct {
+		Type string    `json:"type"`
+		ID   Base64URL `json:"id"`
+	}
+	authenticatorSelection struct {
+		ResidentKey      string           `json:"residentKey"`
+		UserVerification UserVerification `json:"userVerification"`
+	}
+)
+
+const publicKeyType = "public-key"
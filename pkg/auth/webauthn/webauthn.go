@@ -0,0 +1,510 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/auth"
+)
+
+type (
+	OptFn func(*RelyingParty)
+	// RelyingParty registers passkeys and signs users in with them
+	RelyingParty struct {
+		id               string
+		name             string
+		origins          []string
+		timeout          time.Duration
+		userVerification UserVerification
+		store            Store
+		challenges       ChallengeStore
+		authConfig       *auth.Config
+		clock            func() time.Time
+	}
+
+	// UserVerification is requirement of user verification, i.e. PIN or biometrics, by authenticator
+	UserVerification string
+
+	// Store persists credentials and looks up users signing in with them
+	Store interface {
+		// Credentials returns credentials of user
+		Credentials(ctx context.Context, userID uuid.UUID) ([]*Credential, error)
+		// Credential returns credential by its ID. Returns ErrCredentialNotFound when it doesn't exist
+		Credential(ctx context.Context, id []byte) (*Credential, error)
+		AddCredential(ctx context.Context, c *Credential) error
+		// UpdateSignCount persists signature counter of credential after successful sign in
+		UpdateSignCount(ctx context.Context, id []byte, signCount uint32) error
+		// CookieValue returns cookie value of user signing in, see auth.NewCookieValue
+		CookieValue(ctx context.Context, userID uuid.UUID) (*auth.CookieValue, error)
+	}
+
+	// ChallengeStore records challenges of finished ceremonies, so that session data and response can't be replayed
+	ChallengeStore interface {
+		// UseChallenge records challenge as used until expires. Returns false when challenge was already used,
+		// check and update must be atomic
+		UseChallenge(ctx context.Context, challenge []byte, expires time.Time) (bool, error)
+	}
+
+	// MemoryChallengeStore keeps used challenges in memory, use shared store when running multiple instances
+	MemoryChallengeStore struct {
+		mu         sync.Mutex
+		clock      func() time.Time
+		challenges map[string]time.Time
+	}
+
+	// User is user registering credential
+	User struct {
+		ID          uuid.UUID
+		Name        string
+		DisplayName string
+	}
+
+	// Credential is registered passkey of user
+	Credential struct {
+		ID     []byte
+		UserID uuid.UUID
+		// PublicKey is COSE_Key of credential
+		PublicKey []byte
+		Algorithm Algorithm
+		SignCount uint32
+		AAGUID    []byte
+		CreatedAt time.Time
+	}
+
+	// SessionData is state of ceremony kept by server between begin and finish, i.e. in server-side session
+	SessionData struct {
+		Challenge          Base64URL        `json:"challenge"`
+		UserID             uuid.UUID        `json:"userId"`
+		AllowedCredentials []Base64URL      `json:"allowedCredentials,omitempty"`
+		UserVerification   UserVerification `json:"userVerification"`
+		Expires            time.Time        `json:"expires"`
+	}
+
+	// Base64URL is byte slice encoded as unpadded base64url in JSON
+	Base64URL []byte
+)
+
+const (
+	UserVerificationRequired    UserVerification = "required"
+	UserVerificationPreferred   UserVerification = "preferred"
+	UserVerificationDiscouraged UserVerification = "discouraged"
+)
+
+// challengeSize is size of random challenge, WebAuthn requires at least 16 bytes
+const challengeSize = 32
+
+var (
+	ErrCredentialNotFound     = errors.New("webauthn: Credential not found")
+	ErrInvalidResponse        = errors.New("webauthn: Invalid authenticator response")
+	ErrChallengeMismatch      = errors.New("webauthn: Challenge doesn't match")
+	ErrSessionExpired         = errors.New("webauthn: Ceremony has expired")
+	ErrOriginMismatch         = errors.New("webauthn: Origin not allowed")
+	ErrRPIDMismatch           = errors.New("webauthn: Relying party ID doesn't match")
+	ErrUserNotPresent         = errors.New("webauthn: User not present")
+	ErrUserNotVerified        = errors.New("webauthn: User not verified")
+	ErrUnsupportedAttestation = errors.New("webauthn: Unsupported attestation")
+	ErrInvalidSignature       = errors.New("webauthn: Invalid signature")
+	ErrSignCountInvalid       = errors.New("webauthn: Signature counter didn't increase, credential may be cloned")
+	ErrChallengeReused        = errors.New("webauthn: Challenge was already used")
+	ErrNoStore                = errors.New("webauthn: Store is required")
+	ErrNoAuthConfig           = errors.New("webauthn: Auth config is required")
+)
+
+var _ ChallengeStore = (*MemoryChallengeStore)(nil)
+
+// New creates relying party of rpID, which is domain of site, i.e. `example.com`. WithStore and WithAuthConfig
+// are required, returns ErrNoStore or ErrNoAuthConfig without them
+func New(rpID, rpName string, opts ...OptFn) (*RelyingParty, error) {
+	rp := &RelyingParty{
+		id:               rpID,
+		name:             rpName,
+		origins:          []string{"https://" + rpID},
+		timeout:          time.Minute * 5,
+		userVerification: UserVerificationPreferred,
+		store:            nil,
+		challenges:       nil,
+		authConfig:       nil,
+		clock:            time.Now,
+	}
+	for _, o := range opts {
+		o(rp)
+	}
+	if rp.store == nil {
+		return nil, ErrNoStore
+	}
+	if rp.authConfig == nil {
+		return nil, ErrNoAuthConfig
+	}
+	if rp.challenges == nil {
+		rp.challenges = NewMemoryChallengeStore(rp.clock)
+	}
+	return rp, nil
+}
+
+// WithOrigins sets origins ceremonies are accepted from. Defaults to `https://<rpID>`
+func WithOrigins(origins ...string) OptFn {
+	return func(rp *RelyingParty) {
+		rp.origins = origins
+	}
+}
+
+// WithTimeout sets how long user has to complete ceremony. Defaults to 5 minutes
+func WithTimeout(d time.Duration) OptFn {
+	return func(rp *RelyingParty) {
+		rp.timeout = d
+	}
+}
+
+// WithUserVerification sets user verification requirement. Defaults to UserVerificationPreferred
+func WithUserVerification(uv UserVerification) OptFn {
+	return func(rp *RelyingParty) {
+		rp.userVerification = uv
+	}
+}
+
+func WithStore(store Store) OptFn {
+	return func(rp *RelyingParty) {
+		rp.store = store
+	}
+}
+
+// WithChallengeStore sets store of used challenges. Defaults to MemoryChallengeStore
+func WithChallengeStore(store ChallengeStore) OptFn {
+	return func(rp *RelyingParty) {
+		rp.challenges = store
+	}
+}
+
+// WithAuthConfig sets config of auth cookie SignIn issues
+func WithAuthConfig(cfg *auth.Config) OptFn {
+	return func(rp *RelyingParty) {
+		rp.authConfig = cfg
+	}
+}
+
+// WithClock replaces time.Now as source of current time
+func WithClock(clock func() time.Time) OptFn {
+	return func(rp *RelyingParty) {
+		rp.clock = clock
+	}
+}
+
+func (rp *RelyingParty) newSession(userID uuid.UUID) (*SessionData, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return &SessionData{
+		Challenge:        challenge,
+		UserID:           userID,
+		UserVerification: rp.userVerification,
+		Expires:          rp.clock().Add(rp.timeout),
+	}, nil
+}
+
+// BeginRegistration creates options for `navigator.credentials.create()`. Session data must be kept
+// until FinishRegistration.
+func (rp *RelyingParty) BeginRegistration(ctx context.Context, user User) (*CreationOptions, *SessionData, error) {
+	session, err := rp.newSession(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := rp.store.Credentials(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &CreationOptions{
+		RP:        rpEntity{ID: rp.id, Name: rp.name},
+		User:      userEntity{ID: user.ID.Bytes(), Name: user.Name, DisplayName: user.DisplayName},
+		Challenge: session.Challenge,
+		PubKeyCredParams: []credentialParameter{
+			{Type: publicKeyType, Alg: AlgorithmES256},
+			{Type: publicKeyType, Alg: AlgorithmEdDSA},
+		},
+		Timeout:     rp.timeout.Milliseconds(),
+		Attestation: "none",
+		AuthenticatorSelection: authenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: rp.userVerification,
+		},
+	}
+	for _, c := range existing {
+		opts.ExcludeCredentials = append(opts.ExcludeCredentials, credentialDescriptor{Type: publicKeyType, ID: c.ID})
+	}
+	return opts, session, nil
+}
+
+// FinishRegistration verifies response of authenticator and stores new credential
+func (rp *RelyingParty) FinishRegistration(ctx context.Context, session *SessionData, resp *RegistrationResponse) (*Credential, error) {
+	clientDataHash, err := rp.verifyClientData(session, resp.Response.ClientDataJSON, "webauthn.create")
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := v.(map[any]any)
+	format, _ := obj["fmt"].(string)
+	attStmt, _ := obj["attStmt"].(map[any]any)
+	rawAuthData, _ := obj["authData"].([]byte)
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err = rp.verifyAuthData(ad, session); err != nil {
+		return nil, err
+	}
+	if ad.credentialID == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	switch format {
+	case "none":
+		if len(attStmt) != 0 {
+			return nil, ErrInvalidResponse
+		}
+	case "packed":
+		// only self attestation, signed by credential key itself, is supported
+		if _, ok := attStmt["x5c"]; ok {
+			return nil, ErrUnsupportedAttestation
+		}
+		alg, _ := attStmt["alg"].(int64)
+		sig, _ := attStmt["sig"].([]byte)
+		if Algorithm(alg) != ad.publicKeyAlg {
+			return nil, ErrInvalidResponse
+		}
+		if !verifySignature(ad.key, ad.publicKeyAlg, append(slices.Clip(rawAuthData), clientDataHash...), sig) {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, ErrUnsupportedAttestation
+	}
+
+	if _, err = rp.store.Credential(ctx, ad.credentialID); err == nil {
+		return nil, ErrInvalidResponse
+	} else if !errors.Is(err, ErrCredentialNotFound) {
+		return nil, err
+	}
+	if err = rp.useChallenge(ctx, session); err != nil {
+		return nil, err
+	}
+	c := &Credential{
+		ID:        slices.Clone(ad.credentialID),
+		UserID:    session.UserID,
+		PublicKey: slices.Clone(ad.publicKey),
+		Algorithm: ad.publicKeyAlg,
+		SignCount: ad.signCount,
+		AAGUID:    slices.Clone(ad.aaguid),
+		CreatedAt: rp.clock(),
+	}
+	if err = rp.store.AddCredential(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// BeginLogin creates options for `navigator.credentials.get()`. With uuid.Nil user, any discoverable credential
+// (passkey) of relying party is accepted, otherwise only credentials of user. Session data must be kept
+// until FinishLogin.
+func (rp *RelyingParty) BeginLogin(ctx context.Context, userID uuid.UUID) (*RequestOptions, *SessionData, error) {
+	session, err := rp.newSession(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &RequestOptions{
+		Challenge:        session.Challenge,
+		Timeout:          rp.timeout.Milliseconds(),
+		RPID:             rp.id,
+		UserVerification: rp.userVerification,
+	}
+	if userID != uuid.Nil {
+		credentials, err := rp.store.Credentials(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range credentials {
+			opts.AllowCredentials = append(opts.AllowCredentials, credentialDescriptor{Type: publicKeyType, ID: c.ID})
+			session.AllowedCredentials = append(session.AllowedCredentials, c.ID)
+		}
+	}
+	return opts, session, nil
+}
+
+// FinishLogin verifies assertion of authenticator, updates signature counter of credential and returns
+// cookie value of its user. Challenge of session is accepted only once, returns ErrChallengeReused on replay
+func (rp *RelyingParty) FinishLogin(ctx context.Context, session *SessionData, resp *AssertionResponse) (*Credential, *auth.CookieValue, error) {
+	c, err := rp.store.Credential(ctx, resp.RawID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.UserID != uuid.Nil && c.UserID != session.UserID {
+		return nil, nil, ErrCredentialNotFound
+	}
+	if len(session.AllowedCredentials) > 0 && !slices.ContainsFunc(session.AllowedCredentials, func(id Base64URL) bool {
+		return subtle.ConstantTimeCompare(id, c.ID) == 1
+	}) {
+		return nil, nil, ErrCredentialNotFound
+	}
+	if len(resp.Response.UserHandle) > 0 && !slices.Equal(resp.Response.UserHandle, c.UserID.Bytes()) {
+		return nil, nil, ErrInvalidResponse
+	}
+
+	clientDataHash, err := rp.verifyClientData(session, resp.Response.ClientDataJSON, "webauthn.get")
+	if err != nil {
+		return nil, nil, err
+	}
+	ad, err := parseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = rp.verifyAuthData(ad, session); err != nil {
+		return nil, nil, err
+	}
+	key, alg, _, err := parsePublicKey(c.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	signed := append(slices.Clip(resp.Response.AuthenticatorData), clientDataHash...)
+	if alg != c.Algorithm || !verifySignature(key, alg, signed, resp.Response.Signature) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	// authenticators without counter always send 0, replay of their assertions is stopped by used challenge only
+	counted := ad.signCount != 0 || c.SignCount != 0
+	if counted && ad.signCount <= c.SignCount {
+		return nil, nil, ErrSignCountInvalid
+	}
+	if err = rp.useChallenge(ctx, session); err != nil {
+		return nil, nil, err
+	}
+	if counted {
+		if err = rp.store.UpdateSignCount(ctx, c.ID, ad.signCount); err != nil {
+			return nil, nil, err
+		}
+		c.SignCount = ad.signCount
+	}
+
+	cv, err := rp.store.CookieValue(ctx, c.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, cv, nil
+}
+
+// SignIn finishes login and issues auth cookie with auth.AuthMethodHardwareKey
+func (rp *RelyingParty) SignIn(w http.ResponseWriter, r *http.Request, session *SessionData, resp *AssertionResponse) (*Credential, error) {
+	c, cv, err := rp.FinishLogin(r.Context(), session, resp)
+	if err != nil {
+		return nil, err
+	}
+	cv.Claims.AuthMethod = auth.AuthMethodHardwareKey
+	if err = auth.IssueCookie(w, r, cv, rp.authConfig); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// verifyClientData verifies client data of ceremony type and returns its hash
+func (rp *RelyingParty) verifyClientData(session *SessionData, clientDataJSON []byte, ceremony string) ([]byte, error) {
+	if !rp.clock().Before(session.Expires) {
+		return nil, ErrSessionExpired
+	}
+	var cd struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Origin    string `json:"origin"`
+	}
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil || cd.Type != ceremony {
+		return nil, ErrInvalidResponse
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cd.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(challenge, session.Challenge) != 1 {
+		return nil, ErrChallengeMismatch
+	}
+	if !slices.Contains(rp.origins, cd.Origin) {
+		return nil, ErrOriginMismatch
+	}
+	sum := sha256.Sum256(clientDataJSON)
+	return sum[:], nil
+}
+
+// useChallenge marks challenge of session as used, so that ceremony can be finished only once
+func (rp *RelyingParty) useChallenge(ctx context.Context, session *SessionData) error {
+	ok, err := rp.challenges.UseChallenge(ctx, session.Challenge, session.Expires)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChallengeReused
+	}
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthData(ad *authenticatorData, session *SessionData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.id))
+	if subtle.ConstantTimeCompare(ad.rpIDHash, rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if session.UserVerification == UserVerificationRequired && ad.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+// NewMemoryChallengeStore creates store of used challenges, expired ones are dropped by clock, defaults to time.Now
+func NewMemoryChallengeStore(clock func() time.Time) *MemoryChallengeStore {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &MemoryChallengeStore{clock: clock, challenges: make(map[string]time.Time)}
+}
+
+func (s *MemoryChallengeStore) UseChallenge(_ context.Context, challenge []byte, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	for k, exp := range s.challenges {
+		if !now.Before(exp) {
+			delete(s.challenges, k)
+		}
+	}
+	if _, ok := s.challenges[string(challenge)]; ok {
+		return false, nil
+	}
+	s.challenges[string(challenge)] = expires
+	return true, nil
+}
+
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON accepts padded and unpadded base64url
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
@@ -0,0 +1,460 @@
+package webauthn
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pudottapommin/golib/pkg/auth"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// cborPair keeps order of map entries of encodeCBOR
+type cborPair struct {
+	k, v any
+}
+
+// encodeCBOR encodes test values, maps are given as []cborPair
+func encodeCBOR(v any) []byte {
+	header := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= 0xffff:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return header(1, uint64(-1-v))
+		}
+		return header(0, uint64(v))
+	case []byte:
+		return append(header(2, uint64(len(v))), v...)
+	case string:
+		return append(header(3, uint64(len(v))), v...)
+	case []cborPair:
+		b := header(5, uint64(len(v)))
+		for _, p := range v {
+			b = append(b, encodeCBOR(p.k)...)
+			b = append(b, encodeCBOR(p.v)...)
+		}
+		return b
+	}
+	panic("unsupported CBOR value")
+}
+
+// softAuthenticator is software authenticator producing responses browser would pass from hardware authenticator
+type softAuthenticator struct {
+	signer       crypto.Signer
+	alg          Algorithm
+	credentialID []byte
+	signCount    uint32
+	// counterless authenticators, i.e. most synced passkeys, always send 0
+	counterless bool
+	flags       byte
+	origin      string
+}
+
+func newSoftAuthenticator(t *testing.T, alg Algorithm) *softAuthenticator {
+	t.Helper()
+	a := &softAuthenticator{alg: alg, credentialID: make([]byte, 16), flags: flagUserPresent | flagUserVerified, origin: testOrigin}
+	_, _ = rand.Read(a.credentialID)
+	var err error
+	if alg == AlgorithmEdDSA {
+		_, a.signer, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		a.signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	switch pub := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		return encodeCBOR([]cborPair{
+			{coseKeyType, coseKeyTypeEC2}, {coseAlgorithm, int(AlgorithmES256)}, {coseCurve, coseCurveP256},
+			{coseX, pub.X.FillBytes(make([]byte, 32))}, {coseY, pub.Y.FillBytes(make([]byte, 32))},
+		})
+	case ed25519.PublicKey:
+		return encodeCBOR([]cborPair{
+			{coseKeyType, coseKeyTypeOKP}, {coseAlgorithm, int(AlgorithmEdDSA)}, {coseCurve, coseCurveEd25519},
+			{coseX, []byte(pub)},
+		})
+	}
+	panic("unsupported key")
+}
+
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	flags := a.flags
+	if attested {
+		flags |= flagAttestedCredData
+	}
+	b := append(rpIDHash[:], flags)
+	b = binary.BigEndian.AppendUint32(b, a.signCount)
+	if attested {
+		b = append(b, make([]byte, 16)...)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(a.credentialID)))
+		b = append(b, a.credentialID...)
+		b = append(b, a.coseKey()...)
+	}
+	return b
+}
+
+func (a *softAuthenticator) clientData(ceremony string, challenge []byte) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	return data
+}
+
+func (a *softAuthenticator) sign(t *testing.T, authData, clientData []byte) []byte {
+	t.Helper()
+	hash := sha256.Sum256(clientData)
+	signed := append(slices.Clone(authData), hash[:]...)
+	var (
+		sig []byte
+		err error
+	)
+	if a.alg == AlgorithmEdDSA {
+		sig, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		sum := sha256.Sum256(signed)
+		sig, err = a.signer.Sign(rand.Reader, sum[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func (a *softAuthenticator) register(t *testing.T, opts *CreationOptions, format string) *RegistrationResponse {
+	t.Helper()
+	clientData := a.clientData("webauthn.create", opts.Challenge)
+	authData := a.authData(true)
+	attStmt := []cborPair{}
+	if format == "packed" {
+		attStmt = []cborPair{{"alg", int(a.alg)}, {"sig", a.sign(t, authData, clientData)}}
+	}
+	resp := &RegistrationResponse{ID: base64.RawURLEncoding.EncodeToString(a.credentialID), RawID: a.credentialID, Type: publicKeyType}
+	resp.Response.ClientDataJSON = clientData
+	resp.Response.AttestationObject = encodeCBOR([]cborPair{{"fmt", format}, {"attStmt", attStmt}, {"authData", authData}})
+	return roundTrip(t, resp)
+}
+
+func (a *softAuthenticator) assert(t *testing.T, opts *RequestOptions, userID uuid.UUID) *AssertionResponse {
+	t.Helper()
+	if !a.counterless {
+		a.signCount++
+	}
+	clientData := a.clientData("webauthn.get", opts.Challenge)
+	authData := a.authData(false)
+	resp := &AssertionResponse{ID: base64.RawURLEncoding.EncodeToString(a.credentialID), RawID: a.credentialID, Type: publicKeyType}
+	resp.Response.ClientDataJSON = clientData
+	resp.Response.AuthenticatorData = authData
+	resp.Response.Signature = a.sign(t, authData, clientData)
+	resp.Response.UserHandle = userID.Bytes()
+	return roundTrip(t, resp)
+}
+
+// roundTrip passes value through JSON like browser response
+func roundTrip[T any](t *testing.T, v *T) *T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(T)
+	if err = json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+type testStore struct {
+	credentials map[string]*Credential
+	users       map[uuid.UUID]*auth.CookieValue
+}
+
+func (s *testStore) Credentials(_ context.Context, userID uuid.UUID) ([]*Credential, error) {
+	var out []*Credential
+	for _, c := range s.credentials {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *testStore) Credential(_ context.Context, id []byte) (*Credential, error) {
+	c, ok := s.credentials[string(id)]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	cc := *c
+	return &cc, nil
+}
+
+func (s *testStore) AddCredential(_ context.Context, c *Credential) error {
+	s.credentials[string(c.ID)] = c
+	return nil
+}
+
+func (s *testStore) UpdateSignCount(_ context.Context, id []byte, signCount uint32) error {
+	s.credentials[string(id)].SignCount = signCount
+	return nil
+}
+
+func (s *testStore) CookieValue(_ context.Context, userID uuid.UUID) (*auth.CookieValue, error) {
+	cv, ok := s.users[userID]
+	if !ok {
+		return nil, auth.ErrorIdentityNotFound
+	}
+	return auth.NewCookieValue(cv.ID, cv.Username, cv.SecurityStamp), nil
+}
+
+func newTestRelyingParty(t *testing.T, opts ...OptFn) (*RelyingParty, *testStore, User) {
+	t.Helper()
+	key, err := auth.NewSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := User{ID: uuid.Must(uuid.NewV4()), Name: "user@example.com", DisplayName: "User"}
+	store := &testStore{
+		credentials: map[string]*Credential{},
+		users:       map[uuid.UUID]*auth.CookieValue{user.ID: auth.NewCookieValue(user.ID, user.Name, []byte("stamp"))},
+	}
+	opts = append([]OptFn{WithStore(store), WithAuthConfig(auth.NewConfig(auth.WithSigningKey(key)))}, opts...)
+	rp, err := New(testRPID, "Example", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp, store, user
+}
+
+func TestNewRequiredOptions(t *testing.T) {
+	store := &testStore{}
+	if _, err := New(testRPID, "Example", WithAuthConfig(auth.NewConfig())); !errors.Is(err, ErrNoStore) {
+		t.Errorf("expected ErrNoStore, got %v", err)
+	}
+	if _, err := New(testRPID, "Example", WithStore(store)); !errors.Is(err, ErrNoAuthConfig) {
+		t.Errorf("expected ErrNoAuthConfig, got %v", err)
+	}
+}
+
+func TestRegistrationAndSignIn(t *testing.T) {
+	for _, alg := range []Algorithm{AlgorithmES256, AlgorithmEdDSA} {
+		for _, format := range []string{"none", "packed"} {
+			t.Run(alg.String()+"/"+format, func(t *testing.T) {
+				ctx := context.Background()
+				rp, store, user := newTestRelyingParty(t)
+				a := newSoftAuthenticator(t, alg)
+
+				creation, session, err := rp.BeginRegistration(ctx, user)
+				if err != nil {
+					t.Fatal(err)
+				}
+				c, err := rp.FinishRegistration(ctx, session, a.register(t, creation, format))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.UserID != user.ID || c.Algorithm != alg || len(store.credentials) != 1 {
+					t.Fatalf("unexpected credential %+v", c)
+				}
+
+				request, session, err := rp.BeginLogin(ctx, uuid.Nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodPost, "/login", nil)
+				if _, err = rp.SignIn(w, r, session, a.assert(t, request, user.ID)); err != nil {
+					t.Fatal(err)
+				}
+				if store.credentials[string(a.credentialID)].SignCount != 1 {
+					t.Error("sign count not updated")
+				}
+
+				r = httptest.NewRequest(http.MethodGet, "/", nil)
+				r.AddCookie(w.Result().Cookies()[0])
+				cv, err := auth.GetCookie(r, rp.authConfig)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cv.ID != user.ID || cv.Claims.AuthMethod != auth.AuthMethodHardwareKey {
+					t.Errorf("unexpected cookie %+v", cv)
+				}
+			})
+		}
+	}
+}
+
+func TestRegistrationRejected(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	rp, _, user := newTestRelyingParty(t, WithClock(func() time.Time { return now }), WithUserVerification(UserVerificationRequired))
+
+	pairs := []struct {
+		name   string
+		modify func(a *softAuthenticator, opts *CreationOptions)
+		err    error
+	}{
+		{"wrong challenge", func(_ *softAuthenticator, opts *CreationOptions) { opts.Challenge = []byte("other") }, ErrChallengeMismatch},
+		{"wrong origin", func(a *softAuthenticator, _ *CreationOptions) { a.origin = "https://evil.example" }, ErrOriginMismatch},
+		{"user not verified", func(a *softAuthenticator, _ *CreationOptions) { a.flags = flagUserPresent }, ErrUserNotVerified},
+		{"user not present", func(a *softAuthenticator, _ *CreationOptions) { a.flags = flagUserVerified }, ErrUserNotPresent},
+	}
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			a := newSoftAuthenticator(t, AlgorithmES256)
+			creation, session, err := rp.BeginRegistration(ctx, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.modify(a, creation)
+			if _, err = rp.FinishRegistration(ctx, session, a.register(t, creation, "packed")); !errors.Is(err, p.err) {
+				t.Errorf("expected %v, got %v", p.err, err)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		a := newSoftAuthenticator(t, AlgorithmES256)
+		creation, session, err := rp.BeginRegistration(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session.Expires = now
+		if _, err = rp.FinishRegistration(ctx, session, a.register(t, creation, "none")); !errors.Is(err, ErrSessionExpired) {
+			t.Errorf("expected ErrSessionExpired, got %v", err)
+		}
+	})
+}
+
+func TestAssertionRejected(t *testing.T) {
+	ctx := context.Background()
+	rp, store, user := newTestRelyingParty(t)
+	a := newSoftAuthenticator(t, AlgorithmEdDSA)
+	creation, session, err := rp.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rp.FinishRegistration(ctx, session, a.register(t, creation, "none")); err != nil {
+		t.Fatal(err)
+	}
+
+	request, session, err := rp.BeginLogin(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(request.AllowCredentials) != 1 {
+		t.Fatalf("expected allowed credential, got %d", len(request.AllowCredentials))
+	}
+
+	resp := a.assert(t, request, user.ID)
+	resp.Response.Signature[0] ^= 1
+	if _, _, err = rp.FinishLogin(ctx, session, resp); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if _, _, err = rp.FinishLogin(ctx, session, a.assert(t, request, user.ID)); err != nil {
+		t.Fatal(err)
+	}
+	// cloned authenticator replays counter
+	a.signCount = store.credentials[string(a.credentialID)].SignCount - 1
+	if _, _, err = rp.FinishLogin(ctx, session, a.assert(t, request, user.ID)); !errors.Is(err, ErrSignCountInvalid) {
+		t.Errorf("expected ErrSignCountInvalid, got %v", err)
+	}
+
+	other := newSoftAuthenticator(t, AlgorithmEdDSA)
+	if _, _, err = rp.FinishLogin(ctx, session, other.assert(t, request, user.ID)); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("expected ErrCredentialNotFound, got %v", err)
+	}
+}
+
+func TestCeremonyReplay(t *testing.T) {
+	ctx := context.Background()
+	rp, _, user := newTestRelyingParty(t)
+	a := newSoftAuthenticator(t, AlgorithmES256)
+	a.counterless = true
+
+	creation, session, err := rp.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := a.register(t, creation, "none")
+	if _, err = rp.FinishRegistration(ctx, session, resp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rp.FinishRegistration(ctx, session, resp); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse, got %v", err)
+	}
+
+	request, session, err := rp.BeginLogin(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion := a.assert(t, request, user.ID)
+	if _, _, err = rp.FinishLogin(ctx, session, assertion); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = rp.FinishLogin(ctx, session, assertion); !errors.Is(err, ErrChallengeReused) {
+		t.Errorf("expected ErrChallengeReused, got %v", err)
+	}
+	if _, _, err = rp.FinishLogin(ctx, session, a.assert(t, request, user.ID)); !errors.Is(err, ErrChallengeReused) {
+		t.Errorf("expected ErrChallengeReused for new assertion of used session, got %v", err)
+	}
+}
+
+func TestMemoryChallengeStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryChallengeStore(func() time.Time { return now })
+	if ok, _ := s.UseChallenge(ctx, []byte("a"), now.Add(time.Minute)); !ok {
+		t.Fatal("expected first use to succeed")
+	}
+	if ok, _ := s.UseChallenge(ctx, []byte("a"), now.Add(time.Minute)); ok {
+		t.Error("expected reuse to fail")
+	}
+	now = now.Add(time.Minute)
+	if ok, _ := s.UseChallenge(ctx, []byte("b"), now.Add(time.Minute)); !ok || len(s.challenges) != 1 {
+		t.Errorf("expected expired challenge to be dropped, got %d", len(s.challenges))
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	for _, data := range [][]byte{{}, {0x5f}, {0x42, 0x01}, {0xa1, 0x40, 0x01}, {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}} {
+		if _, _, err := decodeCBOR(data); !errors.Is(err, ErrMalformedCBOR) {
+			t.Errorf("%x: expected ErrMalformedCBOR, got %v", data, err)
+		}
+	}
+}